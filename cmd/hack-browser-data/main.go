@@ -4,6 +4,8 @@ import (
 	"os"
 	"strings"
 
+	"hack-browser-data/internal/browingdata"
+	"hack-browser-data/internal/browingdata/search"
 	"hack-browser-data/internal/log"
 	"hack-browser-data/internal/provider"
 	"hack-browser-data/internal/utils/fileutil"
@@ -14,12 +16,33 @@ import (
 var (
 	browserName  string
 	outputDir    string
-	outputFormat string
 	verbose      bool
 	compress     bool
 	profilePath  string
+	bookmarkTree bool
+	merge        bool
+	kdbxPassword string
+	kdbxKeyFile  string
+	kdbxCards    bool
+	tmplFile     string
+	layout       string
+	manifest     bool
+	provenance   bool
+	evidence     bool
+	strict       bool
+	zipPassword  string
+	compressFmt  string
+	compressOut  string
+
+	outputFormats cli.StringSlice
+	recipients    cli.StringSlice
+	identities    cli.StringSlice
+	searchEngines cli.StringSlice
+	cookieDomains cli.StringSlice
 )
 
+const version = "0.4.4"
+
 func main() {
 	Execute()
 }
@@ -29,40 +52,189 @@ func Execute() {
 		Name:      "hack-browser-data",
 		Usage:     "Export password|bookmark|cookie|history|credit card|download|localStorage|extension from browser",
 		UsageText: "[hack-browser-data -b chrome -f json -dir results -cc]\nExport all browingdata(password/cookie/history/bookmark) from browser\nGithub Link: https://github.com/moonD4rk/HackBrowserData",
-		Version:   "0.4.4",
+		Version:   version,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "verbose", Aliases: []string{"vv"}, Destination: &verbose, Value: false, Usage: "verbose"},
 			&cli.BoolFlag{Name: "compress", Aliases: []string{"zip"}, Destination: &compress, Value: false, Usage: "compress result to zip"},
+			&cli.StringFlag{Name: "compress-format", Destination: &compressFmt, Value: fileutil.FormatZip, Usage: "archive format " + strings.Join(fileutil.ArchiveFormats(), "|") + ", only for compress"},
+			&cli.StringFlag{Name: "compress-output", Destination: &compressOut, Usage: "archive path, default is in export dir, only for compress"},
+			&cli.StringFlag{Name: "zip-password", EnvVars: []string{"HACK_BROWSER_DATA_ZIP_PASSWORD"}, Destination: &zipPassword, Usage: "encrypt zip with AES-256 password, only for compress"},
+			&cli.StringSliceFlag{Name: "recipient", Destination: &recipients, Usage: "encrypt archive to age X25519 public key, only for compress"},
 			&cli.StringFlag{Name: "browser", Aliases: []string{"b"}, Destination: &browserName, Value: "all", Usage: "available browsers: all|" + strings.Join(provider.ListBrowsers(), "|")},
-			&cli.StringFlag{Name: "results-dir", Aliases: []string{"dir"}, Destination: &outputDir, Value: "results", Usage: "export dir"},
-			&cli.StringFlag{Name: "format", Aliases: []string{"f"}, Destination: &outputFormat, Value: "csv", Usage: "file name csv|json"},
+			&cli.StringFlag{Name: "results-dir", Aliases: []string{"dir"}, Destination: &outputDir, Value: "results", Usage: "export dir, - writes results to stdout"},
+			&cli.StringSliceFlag{Name: "format", Aliases: []string{"f"}, Destination: &outputFormats, Value: cli.NewStringSlice("csv"), Usage: "output formats " + strings.Join(browingdata.OutputFormats(), "|") + ", separated by comma"},
 			&cli.StringFlag{Name: "profile-path", Aliases: []string{"p"}, Destination: &profilePath, Value: "", Usage: "custom profile dir path, get with chrome://version"},
+			&cli.BoolFlag{Name: "bookmark-tree", Destination: &bookmarkTree, Value: false, Usage: "output bookmarks as nested tree, only for json format"},
+			&cli.BoolFlag{Name: "merge", Destination: &merge, Value: false, Usage: "merge results of all browsers into a single file, only for netscape format"},
+			&cli.StringSliceFlag{Name: "cookie-domain", Destination: &cookieDomains, Usage: "only export cookies of the domain and its subdomains, only for cookies-txt format"},
+			&cli.StringFlag{Name: "kdbx-password", EnvVars: []string{"HACK_BROWSER_DATA_KDBX_PASSWORD"}, Destination: &kdbxPassword, Usage: "password of KeePass database, only for kdbx format"},
+			&cli.StringFlag{Name: "kdbx-keyfile", Destination: &kdbxKeyFile, Usage: "key file of KeePass database, only for kdbx format"},
+			&cli.BoolFlag{Name: "kdbx-cards", Destination: &kdbxCards, Value: false, Usage: "also write credit cards into KeePass database, only for kdbx format"},
+			&cli.StringFlag{Name: "template", Destination: &tmplFile, Usage: "text/template file executed for each artifact, only for template format"},
+			&cli.StringFlag{Name: "layout", Destination: &layout, Value: browingdata.LayoutFlat, Usage: "output layout flat|profile, profile writes results/<browser>/<profile>/<artifact>.<ext>"},
+			&cli.BoolFlag{Name: "manifest", Destination: &manifest, Value: false, Usage: "write index.json listing written files, record counts and profile paths"},
+			&cli.BoolFlag{Name: "provenance", Destination: &provenance, Value: false, Usage: "add source path, table and rowid, LevelDB key or JSON pointer and SHA-256 of snapshot to records"},
+			&cli.BoolFlag{Name: "evidence", Destination: &evidence, Value: false, Usage: "write manifest.json with SHA-256, MD5, sizes and MAC times of source files and their copies"},
+			&cli.BoolFlag{Name: "strict", Destination: &strict, Value: false, Usage: "write no results if a source file is changed during copy, only for evidence"},
+			&cli.StringSliceFlag{Name: "search-engine", Destination: &searchEngines, Usage: "custom search engine name:host:param, host is a regexp of hostname"},
 		},
 		HideHelpCommand: true,
+		Commands: []*cli.Command{
+			{
+				Name:      "decrypt",
+				Usage:     "decrypt and extract the archive of results",
+				ArgsUsage: "results.zip|results.tar.gz|results.tar.zst[.age]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "zip-password", EnvVars: []string{"HACK_BROWSER_DATA_ZIP_PASSWORD"}, Destination: &zipPassword, Usage: "password of AES-256 encrypted zip"},
+					&cli.StringSliceFlag{Name: "identity", Aliases: []string{"i"}, Destination: &identities, Usage: "age identity file of X25519 private key"},
+					&cli.StringFlag{Name: "results-dir", Aliases: []string{"dir"}, Destination: &outputDir, Value: "results", Usage: "extract dir"},
+				},
+				Action: func(c *cli.Context) error {
+					log.Init("notice")
+					if c.Args().Len() != 1 {
+						return cli.ShowSubcommandHelp(c)
+					}
+					options := fileutil.ExtractOptions{
+						Password:   zipPassword,
+						Identities: identities.Value(),
+					}
+					if err := fileutil.ExtractArchive(c.Args().First(), outputDir, options); err != nil {
+						log.Error(err)
+						return nil
+					}
+					log.Noticef("extract to %s success", outputDir)
+					return nil
+				},
+			},
+			{
+				Name:  "schema",
+				Usage: "print the JSON Schema of json format",
+				Action: func(c *cli.Context) error {
+					schema, err := browingdata.JSONSchema()
+					if err != nil {
+						return err
+					}
+					_, err = os.Stdout.Write(schema)
+					return err
+				},
+			},
+		},
 		Action: func(c *cli.Context) error {
+			if outputDir == browingdata.StdoutDir {
+				// keep stdout for results only
+				log.SetOutput(os.Stderr)
+			}
 			if verbose {
 				log.Init("debug")
 			} else {
 				log.Init("notice")
 			}
 
+			for _, e := range searchEngines.Value() {
+				if err := search.AddEngine(e); err != nil {
+					log.Errorf("add search engine %s error %s", e, err)
+				}
+			}
+
+			if compress && outputDir == browingdata.StdoutDir {
+				log.Error("compress is not supported when writing results to stdout")
+				return nil
+			}
+			if manifest && outputDir == browingdata.StdoutDir {
+				log.Error("manifest is not supported when writing results to stdout")
+				return nil
+			}
+			if evidence && outputDir == browingdata.StdoutDir {
+				log.Error("evidence is not supported when writing results to stdout")
+				return nil
+			}
+			if strict && !evidence {
+				log.Error("strict is only supported with evidence")
+				return nil
+			}
+
+			options := browingdata.OutputOptions{
+				BookmarkTree:  bookmarkTree,
+				Merge:         merge,
+				CookieDomains: cookieDomains.Value(),
+				KDBXPassword:  kdbxPassword,
+				KDBXKeyFile:   kdbxKeyFile,
+				KDBXCards:     kdbxCards,
+				Template:      tmplFile,
+				Layout:        layout,
+				Manifest:      manifest,
+				Evidence:      evidence,
+				Strict:        strict,
+				ToolVersion:   version,
+			}
+			outputters, err := browingdata.NewOutputters(outputFormats.Value(), options)
+			if err != nil {
+				log.Error(err)
+				return nil
+			}
+
 			browsers, err := provider.PickBrowsers(browserName, profilePath)
 			if err != nil {
 				log.Error(err)
 			}
 
+			// with strict, browsers are read and verified before any results are written
+			var (
+				names   []string
+				pending []*browingdata.Data
+				refused []string
+			)
 			for _, b := range browsers {
-				data, err := b.BrowsingData()
+				data, err := b.BrowsingData(browingdata.Options{Provenance: provenance, Evidence: evidence})
 				if err != nil {
 					log.Error(err)
+					continue
+				}
+				if evidence {
+					if err := data.VerifyEvidence(); err != nil {
+						if strict {
+							log.Errorf("refuse %s: %s", b.Name(), err)
+							refused = append(refused, b.Name())
+						} else {
+							log.Warnf("%s: %s", b.Name(), err)
+						}
+					}
+				}
+				if strict {
+					names = append(names, b.Name())
+					pending = append(pending, data)
+					continue
+				}
+				data.Output(outputDir, b.Name(), outputters)
+			}
+			if len(refused) > 0 {
+				// no results are written, only the evidence manifest shows what was read
+				if err := browingdata.OutputEvidence(outputDir, pending, outputters); err != nil {
+					log.Error(err)
+				}
+				return cli.Exit("refuse to write results of "+strings.Join(refused, ", ")+", source changed during copy", 1)
+			}
+			for i, data := range pending {
+				data.Output(outputDir, names[i], outputters)
+			}
+			for _, o := range outputters {
+				if err := o.Close(outputDir); err != nil {
+					log.Error(err)
 				}
-				data.Output(outputDir, b.Name(), outputFormat)
 			}
 			if compress {
-				if err = fileutil.CompressDir(outputDir); err != nil {
+				archive, err := fileutil.CompressDir(outputDir, fileutil.CompressOptions{
+					Format:     compressFmt,
+					Output:     compressOut,
+					Password:   zipPassword,
+					Recipients: recipients.Value(),
+					Files:      browingdata.OutputFiles(outputters),
+				})
+				if err != nil {
 					log.Error(err)
+					return nil
 				}
-				log.Noticef("compress success")
+				log.Noticef("compress to %s success", archive)
 			}
 			return nil
 		},
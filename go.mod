@@ -3,26 +3,35 @@ module hack-browser-data
 go 1.19
 
 require (
+	filippo.io/age v1.0.0
 	github.com/gocarina/gocsv v0.0.0-20220927221512-ad3251f9fa25
 	github.com/godbus/dbus/v5 v5.1.0
 	github.com/gookit/color v1.5.2
 	github.com/gookit/slog v0.3.4
+	github.com/klauspost/compress v1.16.7
 	github.com/mattn/go-sqlite3 v1.14.16
-	github.com/otiai10/copy v1.7.0
 	github.com/ppacher/go-dbus-keyring v1.0.1
 	github.com/syndtr/goleveldb v1.0.0
 	github.com/tidwall/gjson v1.14.3
+	github.com/tobischo/gokeepasslib/v3 v3.5.0
 	github.com/urfave/cli/v2 v2.23.0
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
 	golang.org/x/crypto v0.1.0
-	golang.org/x/exp v0.0.0-20221028150844-83b7d23a625f
+	golang.org/x/exp v0.0.0-20230105202349-8879d0199aa3
 	golang.org/x/text v0.4.0
 )
 
 require (
+	github.com/aead/argon2 v0.0.0-20180111183520-a87724528b07 // indirect
+	github.com/aead/chacha20 v0.0.0-20180709150244-8b13a72661da // indirect
+	github.com/apache/arrow/go/arrow v0.0.0-20200730104253-651201b0f516 // indirect
+	github.com/apache/thrift v0.14.2 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
-	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db // indirect
+	github.com/golang/snappy v0.0.3 // indirect
 	github.com/gookit/goutil v0.5.15 // indirect
 	github.com/gookit/gsr v0.0.8 // indirect
+	github.com/pierrec/lz4/v4 v4.1.8 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
@@ -30,4 +39,5 @@ require (
 	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 	golang.org/x/sys v0.1.0 // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
 )
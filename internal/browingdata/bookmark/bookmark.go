@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"hack-browser-data/internal/item"
@@ -19,69 +20,124 @@ import (
 type ChromiumBookmark []bookmark
 
 type bookmark struct {
-	ID        int64
-	Name      string
-	Type      string
-	URL       string
-	DateAdded time.Time
+	ID        int64     `json:"id" csv:"id"`
+	GUID      string    `json:"guid" csv:"guid"`
+	ParentID  int64     `json:"parent_id" csv:"parent_id"`
+	Name      string    `json:"name" csv:"name"`
+	Type      string    `json:"type" csv:"type"`
+	URL       string    `json:"url" csv:"url"`
+	Root      string    `json:"root" csv:"root"`
+	Path      string    `json:"path" csv:"path"`
+	Tags      string    `json:"tags" csv:"tags"`
+	Keyword   string    `json:"keyword" csv:"keyword"`
+	DateAdded time.Time `json:"date_added" csv:"date_added"`
+	// Provenance is the JSON pointer or row of bookmark
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+	// position is the index of bookmark in its parent folder
+	position     int
+	parentGUID   string
+	dateModified time.Time
 }
 
+const (
+	typeURL       = "url"
+	typeFolder    = "folder"
+	typeSeparator = "separator"
+	pathSeparator = "/"
+)
+
 func (c *ChromiumBookmark) Parse(masterKey []byte) error {
-	bookmarks, err := fileutil.ReadFile(item.TempChromiumBookmark)
+	bookmarks, err := parseChromiumBookmark(item.TempChromiumBookmark)
 	if err != nil {
 		return err
 	}
 	defer os.Remove(item.TempChromiumBookmark)
+	*c = bookmarks
+	// TODO: refactor with go generics
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].DateAdded.After((*c)[j].DateAdded)
+	})
+	return nil
+}
+
+// parseChromiumBookmark returns all nodes of the bookmark file in their original order
+func parseChromiumBookmark(filename string) (ChromiumBookmark, error) {
+	bookmarks, err := fileutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var c ChromiumBookmark
 	r := gjson.Parse(bookmarks)
 	if r.Exists() {
+		var position int
 		roots := r.Get("roots")
 		roots.ForEach(func(key, value gjson.Result) bool {
-			getBookmarkChildren(value, c)
+			getBookmarkChildren(value, key.String(), item.JSONPointer("roots", key.String()), nil, position, &c)
+			position++
 			return true
 		})
 	}
-	// TODO: refactor with go generics
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].DateAdded.After((*c)[j].DateAdded)
-	})
-	return nil
+	return c, nil
 }
 
-func getBookmarkChildren(value gjson.Result, w *ChromiumBookmark) (children gjson.Result) {
+// getBookmarkChildren appends the node and all of its descendants to w, pointer is the JSON pointer of node
+// and parent is nil for root folders.
+func getBookmarkChildren(value gjson.Result, root, pointer string, parent *bookmark, position int, w *ChromiumBookmark) {
 	const (
 		bookmarkID       = "id"
+		bookmarkGUID     = "guid"
 		bookmarkAdded    = "date_added"
+		bookmarkModified = "date_modified"
 		bookmarkURL      = "url"
 		bookmarkName     = "name"
 		bookmarkType     = "type"
 		bookmarkChildren = "children"
 	)
 	nodeType := value.Get(bookmarkType)
+	if !nodeType.Exists() {
+		return
+	}
 	bm := bookmark{
-		ID:        value.Get(bookmarkID).Int(),
-		Name:      value.Get(bookmarkName).String(),
-		URL:       value.Get(bookmarkURL).String(),
-		DateAdded: typeutil.TimeEpoch(value.Get(bookmarkAdded).Int()),
-	}
-	children = value.Get(bookmarkChildren)
-	if nodeType.Exists() {
-		bm.Type = nodeType.String()
-		*w = append(*w, bm)
-		if children.Exists() && children.IsArray() {
-			for _, v := range children.Array() {
-				children = getBookmarkChildren(v, w)
-			}
+		ID:           value.Get(bookmarkID).Int(),
+		GUID:         value.Get(bookmarkGUID).String(),
+		Name:         value.Get(bookmarkName).String(),
+		Type:         nodeType.String(),
+		URL:          value.Get(bookmarkURL).String(),
+		Root:         root,
+		DateAdded:    typeutil.TimeEpoch(value.Get(bookmarkAdded).Int()),
+		position:     position,
+		dateModified: typeutil.TimeEpoch(value.Get(bookmarkModified).Int()),
+		Provenance:   item.PointerProvenance(pointer),
+	}
+	if parent != nil {
+		bm.ParentID = parent.ID
+		bm.parentGUID = parent.GUID
+		bm.Path = joinPath(parent.Path, parent.Name)
+	}
+	*w = append(*w, bm)
+	children := value.Get(bookmarkChildren)
+	if children.Exists() && children.IsArray() {
+		for i, v := range children.Array() {
+			getBookmarkChildren(v, root, pointer+item.JSONPointer(bookmarkChildren, i), &bm, i, w)
 		}
 	}
-	return children
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + pathSeparator + name
 }
 
 func bookmarkType(a int64) string {
 	switch a {
 	case 1:
-		return "url"
+		return typeURL
+	case 3:
+		return typeSeparator
 	default:
-		return "folder"
+		return typeFolder
 	}
 }
 
@@ -93,13 +149,37 @@ func (c *ChromiumBookmark) Length() int {
 	return len(*c)
 }
 
+// Tree returns bookmarks nested in their folders
+func (c *ChromiumBookmark) Tree() []*Node {
+	return buildTree(*c)
+}
+
 type FirefoxBookmark []bookmark
 
 const (
-	queryFirefoxBookMark = `SELECT id, url, type, dateAdded, title FROM (SELECT * FROM moz_bookmarks INNER JOIN moz_places ON moz_bookmarks.fk=moz_places.id)`
-	closeJournalMode     = `PRAGMA journal_mode=off`
+	// keywords of a place are aggregated, so a bookmark with several keywords is a single row
+	queryFirefoxBookMark = `SELECT b.id, b.parent, b.type, b.position, COALESCE(b.title, ''), COALESCE(b.dateAdded, 0), b.guid,
+		COALESCE(b.fk, 0), COALESCE(p.url, ''), COALESCE((SELECT GROUP_CONCAT(k.keyword, ',') FROM moz_keywords k WHERE k.place_id = b.fk), '')
+		FROM moz_bookmarks b
+		LEFT JOIN moz_places p ON b.fk = p.id`
+	queryFirefoxTag = `SELECT t.fk, COALESCE(f.title, '') FROM moz_bookmarks t INNER JOIN moz_bookmarks f ON t.parent = f.id
+		WHERE f.parent = (SELECT id FROM moz_bookmarks WHERE guid = ?) AND t.fk IS NOT NULL`
+	closeJournalMode = `PRAGMA journal_mode=off`
 )
 
+// firefox stores tags as folders of the tags root, which contain a bookmark of each tagged place.
+const (
+	firefoxRootGUID = "root________"
+	firefoxTagsGUID = "tags________"
+)
+
+var firefoxRoots = map[string]string{
+	"menu________": "menu",
+	"toolbar_____": "toolbar",
+	"unfiled_____": "unfiled",
+	"mobile______": "mobile",
+}
+
 func (f *FirefoxBookmark) Parse(masterKey []byte) error {
 	var (
 		err          error
@@ -116,26 +196,50 @@ func (f *FirefoxBookmark) Parse(masterKey []byte) error {
 	if err != nil {
 		log.Error(err)
 	}
+	tags, err := getFirefoxTags(keyDB)
+	if err != nil {
+		log.Error(err)
+	}
 	bookmarkRows, err = keyDB.Query(queryFirefoxBookMark)
 	if err != nil {
 		return err
 	}
 	defer bookmarkRows.Close()
+	bookmarks := make(map[int64]*bookmark)
 	for bookmarkRows.Next() {
 		var (
-			id, bType, dateAdded int64
-			title, url           string
+			id, parent, bType, dateAdded, placeID int64
+			position                              int
+			title, guid, url, keyword             string
 		)
-		if err = bookmarkRows.Scan(&id, &url, &bType, &dateAdded, &title); err != nil {
+		if err = bookmarkRows.Scan(&id, &parent, &bType, &position, &title, &dateAdded, &guid, &placeID, &url, &keyword); err != nil {
 			log.Warn(err)
 		}
-		*f = append(*f, bookmark{
-			ID:        id,
-			Name:      title,
-			Type:      bookmarkType(bType),
-			URL:       url,
-			DateAdded: typeutil.TimeStamp(dateAdded / 1000000),
-		})
+		bookmarks[id] = &bookmark{
+			ID:         id,
+			GUID:       guid,
+			ParentID:   parent,
+			Name:       title,
+			Type:       bookmarkType(bType),
+			URL:        url,
+			Tags:       strings.Join(tags[placeID], ","),
+			Keyword:    sortKeywords(keyword),
+			DateAdded:  typeutil.TimeStamp(dateAdded / 1000000),
+			Provenance: item.RowProvenance("moz_bookmarks", id),
+			position:   position,
+		}
+	}
+	for _, bm := range bookmarks {
+		root, path, ok := firefoxBookmarkPath(bm, bookmarks)
+		// skip the invisible root folder and tags
+		if !ok {
+			continue
+		}
+		if parent, exist := bookmarks[bm.ParentID]; !exist || parent.GUID == firefoxRootGUID {
+			bm.ParentID = 0
+		}
+		bm.Root, bm.Path = root, path
+		*f = append(*f, *bm)
 	}
 	sort.Slice(*f, func(i, j int) bool {
 		return (*f)[i].DateAdded.After((*f)[j].DateAdded)
@@ -143,6 +247,64 @@ func (f *FirefoxBookmark) Parse(masterKey []byte) error {
 	return nil
 }
 
+// getFirefoxTags returns tags of places keyed by place id
+func getFirefoxTags(db *sql.DB) (map[int64][]string, error) {
+	rows, err := db.Query(queryFirefoxTag, firefoxTagsGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			placeID int64
+			tag     string
+		)
+		if err := rows.Scan(&placeID, &tag); err != nil {
+			return nil, err
+		}
+		tags[placeID] = append(tags[placeID], tag)
+	}
+	for _, v := range tags {
+		sort.Strings(v)
+	}
+	return tags, rows.Err()
+}
+
+// sortKeywords sorts the comma separated keywords, which are aggregated in no particular order
+func sortKeywords(keywords string) string {
+	if keywords == "" {
+		return ""
+	}
+	s := strings.Split(keywords, ",")
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+// firefoxBookmarkPath returns the root and folder path of bm by walking up its parents,
+// ok is false for the invisible root folder and bookmarks under the tags root.
+func firefoxBookmarkPath(bm *bookmark, bookmarks map[int64]*bookmark) (root, path string, ok bool) {
+	if bm.GUID == firefoxRootGUID {
+		return "", "", false
+	}
+	if r, exist := firefoxRoots[bm.GUID]; exist {
+		return r, "", true
+	}
+	var folders []string
+	// the depth is limited in case of corrupted parent references
+	for parent, depth := bookmarks[bm.ParentID], 0; parent != nil && depth < 256; parent, depth = bookmarks[parent.ParentID], depth+1 {
+		guid := parent.GUID
+		if guid == firefoxTagsGUID || guid == firefoxRootGUID {
+			return "", "", false
+		}
+		folders = append(folders, parent.Name)
+		if r, exist := firefoxRoots[guid]; exist {
+			return r, strings.Join(typeutil.Reverse(folders), pathSeparator), true
+		}
+	}
+	return "", strings.Join(typeutil.Reverse(folders), pathSeparator), true
+}
+
 func (f *FirefoxBookmark) Name() string {
 	return "bookmark"
 }
@@ -150,3 +312,8 @@ func (f *FirefoxBookmark) Name() string {
 func (f *FirefoxBookmark) Length() int {
 	return len(*f)
 }
+
+// Tree returns bookmarks nested in their folders
+func (f *FirefoxBookmark) Tree() []*Node {
+	return buildTree(*f)
+}
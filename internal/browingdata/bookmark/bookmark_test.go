@@ -0,0 +1,130 @@
+package bookmark
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hack-browser-data/internal/item"
+)
+
+func TestParseChromiumBookmark(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "Bookmarks")
+	const bookmarks = `{"roots": {
+		"bookmark_bar": {"id": "1", "guid": "bar", "name": "Bookmarks bar", "type": "folder", "children": [
+			{"id": "2", "guid": "dev", "name": "Dev", "type": "folder", "children": [
+				{"id": "3", "guid": "go", "name": "Go", "type": "url", "url": "https://go.dev", "date_added": "13285566245000000"}
+			]}
+		]},
+		"other": {"id": "4", "guid": "other", "name": "Other bookmarks", "type": "folder", "children": [
+			{"id": "5", "guid": "gh", "name": "GitHub", "type": "url", "url": "https://github.com"}
+		]},
+		"sync_transaction_version": "1"
+	}}`
+	if err := os.WriteFile(filename, []byte(bookmarks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := parseChromiumBookmark(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bookmark)
+	for _, bm := range c {
+		got[bm.GUID] = bm
+	}
+	if len(got) != 5 {
+		t.Fatalf("got %d bookmarks, want 5", len(got))
+	}
+	testCases := []struct {
+		guid, root, path, pointer string
+		parentID                  int64
+	}{
+		{"bar", "bookmark_bar", "", "/roots/bookmark_bar", 0},
+		{"dev", "bookmark_bar", "Bookmarks bar", "/roots/bookmark_bar/children/0", 1},
+		{"go", "bookmark_bar", "Bookmarks bar/Dev", "/roots/bookmark_bar/children/0/children/0", 2},
+		{"other", "other", "", "/roots/other", 0},
+		{"gh", "other", "Other bookmarks", "/roots/other/children/0", 4},
+	}
+	for _, tc := range testCases {
+		bm := got[tc.guid]
+		if bm.Root != tc.root || bm.Path != tc.path || bm.ParentID != tc.parentID || bm.Provenance.Pointer != tc.pointer {
+			t.Errorf("got %s root %s path %q parent %d pointer %s, want %s %q %d %s",
+				tc.guid, bm.Root, bm.Path, bm.ParentID, bm.Provenance.Pointer, tc.root, tc.path, tc.parentID, tc.pointer)
+		}
+	}
+	if added := got["go"].DateAdded.UTC().Unix(); added != 1641092645 {
+		t.Errorf("got date added %d, want 1641092645", added)
+	}
+}
+
+func TestFirefoxBookmark(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite3", item.TempFirefoxBookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		"CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT)",
+		"CREATE TABLE moz_keywords (id INTEGER PRIMARY KEY, keyword TEXT UNIQUE, place_id INTEGER)",
+		`CREATE TABLE moz_bookmarks (id INTEGER PRIMARY KEY, type INTEGER, fk INTEGER, parent INTEGER, position INTEGER,
+			title TEXT, dateAdded INTEGER, guid TEXT)`,
+		"INSERT INTO moz_places VALUES (1, 'https://go.dev'), (2, 'https://github.com')",
+		"INSERT INTO moz_keywords VALUES (1, 'golang', 1), (2, 'go', 1)",
+		`INSERT INTO moz_bookmarks VALUES
+			(1, 2, NULL, 0, 0, '', 0, 'root________'),
+			(2, 2, NULL, 1, 0, 'toolbar', 0, 'toolbar_____'),
+			(3, 2, NULL, 1, 1, 'tags', 0, 'tags________'),
+			(4, 2, NULL, 2, 0, 'Dev', 0, 'dev'),
+			(5, 1, 1, 4, 0, 'Go', 1641092645000000, 'go'),
+			(6, 1, 2, 2, 1, 'GitHub', 0, 'gh'),
+			(7, 2, NULL, 3, 0, 'lang', 0, 'tag-lang'),
+			(8, 1, 1, 7, 0, NULL, 0, 'tagged-go'),
+			(9, 2, NULL, 3, 1, 'code', 0, 'tag-code'),
+			(10, 1, 1, 9, 0, NULL, 0, 'tagged-go-code')`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	f := &FirefoxBookmark{}
+	if err := f.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	// the invisible root, tags and tagged places aren't bookmarks
+	got := make(map[string]bookmark)
+	for _, bm := range *f {
+		if _, ok := got[bm.GUID]; ok {
+			t.Errorf("got duplicated bookmark %s", bm.GUID)
+		}
+		got[bm.GUID] = bm
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d bookmarks %v, want toolbar, Dev, Go and GitHub", len(got), *f)
+	}
+	testCases := []struct {
+		guid, root, path, tags, keyword string
+		parentID                        int64
+	}{
+		{"toolbar_____", "toolbar", "", "", "", 0},
+		{"dev", "toolbar", "toolbar", "", "", 2},
+		{"go", "toolbar", "toolbar/Dev", "code,lang", "go,golang", 4},
+		{"gh", "toolbar", "toolbar", "", "", 2},
+	}
+	for _, tc := range testCases {
+		bm := got[tc.guid]
+		if bm.Root != tc.root || bm.Path != tc.path || bm.Tags != tc.tags || bm.Keyword != tc.keyword || bm.ParentID != tc.parentID {
+			t.Errorf("got %s root %s path %q tags %q keyword %q parent %d, want %s %q %q %q %d",
+				tc.guid, bm.Root, bm.Path, bm.Tags, bm.Keyword, bm.ParentID, tc.root, tc.path, tc.tags, tc.keyword, tc.parentID)
+		}
+	}
+	if url := got["go"].URL; url != "https://go.dev" {
+		t.Errorf("got url %s, want https://go.dev", url)
+	}
+}
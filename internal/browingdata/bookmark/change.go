@@ -0,0 +1,145 @@
+package bookmark
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/item"
+)
+
+// ChromiumBookmarkChange is the difference between Bookmarks.bak and Bookmarks,
+// chromium writes the backup at startup, so these are changes made since the browser was started.
+type ChromiumBookmarkChange []change
+
+type change struct {
+	GUID      string    `json:"guid" csv:"guid"`
+	Change    string    `json:"change" csv:"change"`
+	Type      string    `json:"type" csv:"type"`
+	Name      string    `json:"name" csv:"name"`
+	OldName   string    `json:"old_name" csv:"old_name"`
+	URL       string    `json:"url" csv:"url"`
+	Path      string    `json:"path" csv:"path"`
+	OldPath   string    `json:"old_path" csv:"old_path"`
+	DateAdded time.Time `json:"date_added" csv:"date_added"`
+	// ChangeTime is the date_modified of the parent folder, which is updated when children are changed
+	ChangeTime time.Time `json:"change_time" csv:"change_time"`
+	// Provenance is the bookmark in Bookmarks, or in Bookmarks.bak if it's removed
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeRenamed = "renamed"
+	changeMoved   = "moved"
+)
+
+func (c *ChromiumBookmarkChange) Parse(masterKey []byte) error {
+	defer os.RemoveAll(item.TempChromiumBookmarkChange)
+	current, err := parseChromiumBookmark(filepath.Join(item.TempChromiumBookmarkChange, item.ChromiumBookmark.FileName()))
+	if err != nil {
+		return err
+	}
+	backup, err := parseChromiumBookmark(filepath.Join(item.TempChromiumBookmarkChange, item.ChromiumBookmarkChange.FileName()))
+	if err != nil {
+		return err
+	}
+	setFile(current, item.ChromiumBookmark.FileName())
+	setFile(backup, item.ChromiumBookmarkChange.FileName())
+	*c = diffBookmarks(backup, current)
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].ChangeTime.After((*c)[j].ChangeTime)
+	})
+	return nil
+}
+
+func (c *ChromiumBookmarkChange) Name() string {
+	return "bookmarkChange"
+}
+
+func (c *ChromiumBookmarkChange) Length() int {
+	return len(*c)
+}
+
+// diffBookmarks compares bookmarks by guid and returns the changes from old to current
+func diffBookmarks(old, current []bookmark) []change {
+	var (
+		changes    []change
+		oldByGUID  = bookmarksByGUID(old)
+		currByGUID = bookmarksByGUID(current)
+	)
+	for _, bm := range current {
+		o, ok := oldByGUID[bm.GUID]
+		if !ok {
+			changes = append(changes, newChange(changeAdded, bm, bm.DateAdded))
+			continue
+		}
+		var kinds []string
+		if o.Name != bm.Name {
+			kinds = append(kinds, changeRenamed)
+		}
+		if o.parentGUID != bm.parentGUID {
+			kinds = append(kinds, changeMoved)
+		}
+		if len(kinds) == 0 {
+			continue
+		}
+		ch := newChange(strings.Join(kinds, "|"), bm, parentModified(bm, currByGUID))
+		ch.OldName, ch.OldPath = o.Name, o.Path
+		changes = append(changes, ch)
+	}
+	for _, bm := range old {
+		if _, ok := currByGUID[bm.GUID]; ok {
+			continue
+		}
+		changeTime := bm.dateModified
+		if parent, ok := currByGUID[bm.parentGUID]; ok {
+			changeTime = parent.dateModified
+		}
+		changes = append(changes, newChange(changeRemoved, bm, changeTime))
+	}
+	return changes
+}
+
+func newChange(kind string, bm bookmark, changeTime time.Time) change {
+	return change{
+		GUID:       bm.GUID,
+		Change:     kind,
+		Type:       bm.Type,
+		Name:       bm.Name,
+		URL:        bm.URL,
+		Path:       bm.Path,
+		DateAdded:  bm.DateAdded,
+		ChangeTime: changeTime,
+		Provenance: bm.Provenance,
+	}
+}
+
+// setFile sets the file of bookmarks in provenance
+func setFile(bookmarks []bookmark, filename string) {
+	for _, bm := range bookmarks {
+		if bm.Provenance != nil {
+			bm.Provenance.File = filename
+		}
+	}
+}
+
+func parentModified(bm bookmark, bookmarks map[string]bookmark) time.Time {
+	if parent, ok := bookmarks[bm.parentGUID]; ok {
+		return parent.dateModified
+	}
+	return bm.dateModified
+}
+
+func bookmarksByGUID(bookmarks []bookmark) map[string]bookmark {
+	m := make(map[string]bookmark, len(bookmarks))
+	for _, bm := range bookmarks {
+		if bm.GUID != "" {
+			m[bm.GUID] = bm
+		}
+	}
+	return m
+}
@@ -0,0 +1,39 @@
+package bookmark
+
+import (
+	"testing"
+)
+
+func TestDiffBookmarks(t *testing.T) {
+	t.Parallel()
+
+	old := []bookmark{
+		{GUID: "bar", Name: "Bookmarks bar", Type: typeFolder},
+		{GUID: "dev", Name: "Dev", Type: typeFolder, parentGUID: "bar"},
+		{GUID: "go", Name: "Go", Type: typeURL, parentGUID: "bar"},
+		{GUID: "gh", Name: "GitHub", Type: typeURL, parentGUID: "dev"},
+		{GUID: "gone", Name: "Gone", Type: typeURL, parentGUID: "dev"},
+	}
+	current := []bookmark{
+		{GUID: "bar", Name: "Bookmarks bar", Type: typeFolder},
+		{GUID: "dev", Name: "Develop", Type: typeFolder, parentGUID: "bar"},
+		{GUID: "go", Name: "Golang", Type: typeURL, parentGUID: "dev"},
+		{GUID: "gh", Name: "GitHub", Type: typeURL, parentGUID: "dev"},
+		{GUID: "new", Name: "New", Type: typeURL, parentGUID: "bar"},
+	}
+	want := map[string]string{
+		"dev":  changeRenamed,
+		"go":   changeRenamed + "|" + changeMoved,
+		"new":  changeAdded,
+		"gone": changeRemoved,
+	}
+	changes := diffBookmarks(old, current)
+	if len(changes) != len(want) {
+		t.Fatalf("diffBookmarks() returned %d changes, want %d", len(changes), len(want))
+	}
+	for _, c := range changes {
+		if want[c.GUID] != c.Change {
+			t.Errorf("change of %s = %s, want %s", c.GUID, c.Change, want[c.GUID])
+		}
+	}
+}
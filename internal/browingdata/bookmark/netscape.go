@@ -0,0 +1,79 @@
+package bookmark
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const netscapeHeader = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+`
+
+// toolbar roots are marked as PERSONAL_TOOLBAR_FOLDER, browsers import them into their bookmark bar
+var toolbarRoots = map[string]bool{
+	"bookmark_bar": true,
+	"toolbar":      true,
+}
+
+// WriteNetscape writes nodes in the Netscape bookmark file format, which could be imported by all browsers
+func WriteNetscape(w io.Writer, nodes []*Node) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(netscapeHeader); err != nil {
+		return err
+	}
+	writeNetscapeList(bw, nodes, 0)
+	return bw.Flush()
+}
+
+// NewFolder returns a folder node which contains nodes, used to merge bookmarks of several browsers
+func NewFolder(name string, nodes []*Node) *Node {
+	return &Node{
+		Name:     name,
+		Type:     typeFolder,
+		Children: nodes,
+	}
+}
+
+func writeNetscapeList(w *bufio.Writer, nodes []*Node, depth int) {
+	indent := strings.Repeat("    ", depth)
+	fmt.Fprintf(w, "%s<DL><p>\n", indent)
+	for _, n := range nodes {
+		switch n.Type {
+		case typeFolder:
+			fmt.Fprintf(w, "%s    <DT><H3%s", indent, netscapeAddDate(n))
+			if depth == 0 && toolbarRoots[n.root] {
+				w.WriteString(` PERSONAL_TOOLBAR_FOLDER="true"`)
+			}
+			fmt.Fprintf(w, ">%s</H3>\n", html.EscapeString(n.Name))
+			writeNetscapeList(w, n.Children, depth+1)
+		case typeSeparator:
+			fmt.Fprintf(w, "%s    <HR>\n", indent)
+		default:
+			fmt.Fprintf(w, `%s    <DT><A HREF="%s"%s`, indent, html.EscapeString(n.URL), netscapeAddDate(n))
+			if n.Tags != "" {
+				fmt.Fprintf(w, ` TAGS="%s"`, html.EscapeString(n.Tags))
+			}
+			if n.Keyword != "" {
+				fmt.Fprintf(w, ` SHORTCUTURL="%s"`, html.EscapeString(n.Keyword))
+			}
+			fmt.Fprintf(w, ">%s</A>\n", html.EscapeString(n.Name))
+		}
+	}
+	fmt.Fprintf(w, "%s</DL><p>\n", indent)
+}
+
+// netscapeAddDate returns the ADD_DATE attribute in unix seconds, empty if the date is unknown
+func netscapeAddDate(n *Node) string {
+	if n.DateAdded.Unix() <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(` ADD_DATE="%d"`, n.DateAdded.Unix())
+}
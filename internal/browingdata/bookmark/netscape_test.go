@@ -0,0 +1,48 @@
+package bookmark
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteNetscape(t *testing.T) {
+	t.Parallel()
+
+	added := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	nodes := []*Node{
+		{Name: "Bookmarks bar", Type: typeFolder, DateAdded: added, root: "bookmark_bar", Children: []*Node{
+			{Name: "Go & <Tools>", Type: typeFolder, Children: []*Node{
+				{Name: `"Go" <Playground>`, Type: typeURL, URL: "https://go.dev/play/?v=1&x=<y>", DateAdded: added, Tags: "go,dev", Keyword: "gp"},
+			}},
+			{Type: typeSeparator},
+		}},
+		{Name: "Other bookmarks", Type: typeFolder, root: "other"},
+	}
+	var b bytes.Buffer
+	if err := WriteNetscape(&b, nodes); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n") {
+		t.Errorf("got file without Netscape header: %s", got)
+	}
+	want := `<DL><p>
+    <DT><H3 ADD_DATE="1641092645" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><H3>Go &amp; &lt;Tools&gt;</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev/play/?v=1&amp;x=&lt;y&gt;" ADD_DATE="1641092645" TAGS="go,dev" SHORTCUTURL="gp">&#34;Go&#34; &lt;Playground&gt;</A>
+        </DL><p>
+        <HR>
+    </DL><p>
+    <DT><H3>Other bookmarks</H3>
+    <DL><p>
+    </DL><p>
+</DL><p>
+`
+	if body := strings.TrimPrefix(got, netscapeHeader); body != want {
+		t.Errorf("got bookmarks\n%s\nwant\n%s", body, want)
+	}
+}
@@ -0,0 +1,61 @@
+package bookmark
+
+import (
+	"sort"
+	"time"
+)
+
+// Node is a bookmark with its children, the roots of tree are the root folders of browser.
+type Node struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	URL       string    `json:"url,omitempty"`
+	Tags      string    `json:"tags,omitempty"`
+	Keyword   string    `json:"keyword,omitempty"`
+	DateAdded time.Time `json:"date_added"`
+	Children  []*Node   `json:"children,omitempty"`
+
+	// root is the key of root folder, only set for root folders
+	root     string
+	position int
+}
+
+// buildTree nests bookmarks into their parent folders and keeps their original order,
+// bookmarks whose parent is missing become roots.
+func buildTree(bookmarks []bookmark) []*Node {
+	nodes := make(map[int64]*Node, len(bookmarks))
+	for _, bm := range bookmarks {
+		nodes[bm.ID] = &Node{
+			ID:        bm.ID,
+			Name:      bm.Name,
+			Type:      bm.Type,
+			URL:       bm.URL,
+			Tags:      bm.Tags,
+			Keyword:   bm.Keyword,
+			DateAdded: bm.DateAdded,
+			position:  bm.position,
+		}
+	}
+	var roots []*Node
+	for _, bm := range bookmarks {
+		node := nodes[bm.ID]
+		if parent, ok := nodes[bm.ParentID]; ok && bm.ParentID != bm.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			node.root = bm.Root
+			roots = append(roots, node)
+		}
+	}
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].position < nodes[j].position
+	})
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
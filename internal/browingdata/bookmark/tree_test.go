@@ -0,0 +1,42 @@
+package bookmark
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildTree(t *testing.T) {
+	t.Parallel()
+
+	bookmarks := []bookmark{
+		{ID: 4, ParentID: 2, Name: "Go", Type: typeURL, position: 1},
+		{ID: 1, Name: "Bookmarks bar", Type: typeFolder, Root: "bookmark_bar"},
+		{ID: 3, ParentID: 2, Name: "GitHub", Type: typeURL, position: 0},
+		{ID: 2, ParentID: 1, Name: "Dev", Type: typeFolder},
+		{ID: 5, Name: "Other bookmarks", Type: typeFolder, Root: "other", position: 1},
+		// the parent of bookmark is missing, so it becomes a root
+		{ID: 6, ParentID: 42, Name: "Orphan", Type: typeURL, Root: "other", position: 2},
+		// a bookmark which is its own parent isn't nested into itself
+		{ID: 7, ParentID: 7, Name: "Loop", Type: typeFolder, Root: "synced", position: 3},
+	}
+	roots := buildTree(bookmarks)
+	var names []string
+	for _, r := range roots {
+		names = append(names, r.Name)
+	}
+	if want := []string{"Bookmarks bar", "Other bookmarks", "Orphan", "Loop"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got roots %v, want %v", names, want)
+	}
+	if roots[0].root != "bookmark_bar" || roots[2].root != "other" || len(roots[3].Children) != 0 {
+		t.Errorf("got roots %s %s with %d children of loop", roots[0].root, roots[2].root, len(roots[3].Children))
+	}
+	bar := roots[0]
+	if len(bar.Children) != 1 || bar.Children[0].Name != "Dev" || bar.Children[0].root != "" {
+		t.Fatalf("got children %+v of bookmarks bar, want Dev", bar.Children)
+	}
+	// children keep their positions in the parent folder
+	dev := bar.Children[0].Children
+	if len(dev) != 2 || dev[0].Name != "GitHub" || dev[1].Name != "Go" {
+		t.Errorf("got children %+v of Dev, want GitHub and Go", dev)
+	}
+}
@@ -1,7 +1,9 @@
 package browingdata
 
 import (
-	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"hack-browser-data/internal/browingdata/bookmark"
 	"hack-browser-data/internal/browingdata/cookie"
@@ -11,13 +13,35 @@ import (
 	"hack-browser-data/internal/browingdata/history"
 	"hack-browser-data/internal/browingdata/localstorage"
 	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/browingdata/search"
 	"hack-browser-data/internal/item"
 	"hack-browser-data/internal/log"
 	"hack-browser-data/internal/utils/fileutil"
+	"hack-browser-data/internal/utils/typeutil"
 )
 
 type Data struct {
+	browser string
+	profile string
 	sources map[item.Item]Source
+	// profilePath is the folder of browser profile
+	profilePath string
+	// paths are the files or folders in browser profile which sources are read from
+	paths map[item.Item]string
+	// collected is the time when sources are read
+	collected time.Time
+	// evidence is the hashes of files of items copied from browser profile
+	evidence map[item.Item][]*fileutil.Evidence
+	// provenance is whether records carry where they are read from
+	provenance bool
+}
+
+// Options are the options of reading browsing data from browser profile
+type Options struct {
+	// Provenance adds where each record is read from, the copy of items is hashed before parsing
+	Provenance bool
+	// Evidence hashes files of items before copying and their copies afterwards
+	Evidence bool
 }
 
 type Source interface {
@@ -28,48 +52,151 @@ type Source interface {
 	Length() int
 }
 
-func New(sources []item.Item) *Data {
+// profileSource is implemented by sources whose records carry the browser and profile they are read from
+type profileSource interface {
+	SetProfile(browser, profile string)
+}
+
+func New(browser, profile string, sources []item.Item) *Data {
 	bd := &Data{
+		browser: browser,
+		profile: profile,
 		sources: make(map[item.Item]Source),
 	}
 	bd.addSource(sources)
 	return bd
 }
 
+// SetPaths sets the files or folders of items in browser profile, which are recorded as the sources of outputs
+func (d *Data) SetPaths(paths map[item.Item]string) {
+	d.paths = paths
+}
+
+// SetProfilePath sets the folder of browser profile, which is recorded in the manifest of outputs
+func (d *Data) SetProfilePath(p string) {
+	d.profilePath = p
+}
+
+// SetProvenance sets whether records carry where they are read from
+func (d *Data) SetProvenance(enabled bool) {
+	d.provenance = enabled
+}
+
 func (d *Data) Recovery(masterKey []byte) error {
-	for _, source := range d.sources {
+	d.collected = time.Now()
+	for i, source := range d.sources {
+		var snap snapshot
+		if d.provenance {
+			// the copy of item is removed after parsing
+			var err error
+			if snap, err = hashSnapshot(i.String()); err != nil {
+				log.Debugf("hash snapshot of %s error %s", source.Name(), err.Error())
+			}
+		}
 		if err := source.Parse(masterKey); err != nil {
 			log.Errorf("parse %s error %s", source.Name(), err.Error())
+			continue
+		}
+		if s, ok := source.(profileSource); ok {
+			s.SetProfile(d.browser, d.profile)
+		}
+		if d.provenance {
+			d.setProvenance(i, source, snap)
+		} else {
+			clearProvenance(source)
 		}
 	}
 	return nil
 }
 
-func (d *Data) Output(dir, browserName, flag string) {
-	output := NewOutPutter(flag)
-
-	for _, source := range d.sources {
-		if source.Length() == 0 {
-			// if the length of the export data is 0, then it is not necessary to output
-			continue
+// sourcePath returns the path of file or folder in browser profile which source is read from
+func (d *Data) sourcePath(source Source) string {
+	for i, s := range d.sources {
+		if s == source {
+			return d.paths[i]
 		}
-		filename := fileutil.ItemName(browserName, source.Name(), output.Ext())
+	}
+	return ""
+}
 
-		f, err := output.CreateFile(dir, filename)
-		if err != nil {
-			log.Errorf("create file %s error %s", filename, err.Error())
-			continue
+// Output writes data of the browser with outputters, browserName is the name of browser and its profile
+func (d *Data) Output(dir, browserName string, outputters []Outputter) {
+	for _, o := range outputters {
+		if err := o.Write(dir, browserName, d); err != nil {
+			log.Errorf("output %s error %s", browserName, err.Error())
 		}
-		if err := output.Write(source, f); err != nil {
-			log.Errorf("write to file %s error %s", filename, err.Error())
-			continue
+	}
+}
+
+// MergeBookmarks writes bookmarks of all browsers into a single Netscape bookmark file,
+// bookmarks of each browser are placed in a folder named after the browser.
+func MergeBookmarks(dir string, browsers map[string]*Data) ([]string, error) {
+	names := typeutil.Keys(browsers)
+	sort.Strings(names)
+	var folders []*bookmark.Node
+	for _, name := range names {
+		for _, source := range browsers[name].sources {
+			if t, ok := source.(treeSource); ok && source.Length() > 0 {
+				folders = append(folders, bookmark.NewFolder(name, t.Tree()))
+			}
 		}
-		if err := f.Close(); err != nil {
-			log.Errorf("close file %s error %s", filename, err.Error())
-			continue
+	}
+	if len(folders) == 0 {
+		return nil, nil
+	}
+	f, err := createFile(dir, mergedBookmarkFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := bookmark.WriteNetscape(f, folders); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	log.Noticef("output to file %s success", outputPath(dir, mergedBookmarkFile))
+	return []string{mergedBookmarkFile}, nil
+}
+
+const mergedBookmarkFile = "bookmarks.html"
+
+// MergePasswords writes passwords of all browsers into a single file in the import format of password manager,
+// each browser is a folder or group of the password manager
+func MergePasswords(dir, format string, browsers map[string]*Data) ([]string, error) {
+	names := typeutil.Keys(browsers)
+	sort.Strings(names)
+	var logins []password.Login
+	for _, name := range names {
+		for _, source := range browsers[name].sources {
+			s, ok := source.(loginSource)
+			if !ok {
+				continue
+			}
+			for _, l := range s.Logins() {
+				l.Browser = name
+				logins = append(logins, l)
+			}
 		}
-		log.Noticef("output to file %s success", path.Join(dir, filename))
 	}
+	if len(logins) == 0 {
+		return nil, nil
+	}
+	ext := password.ManagerExt(format)
+	filename := fileutil.ItemName("password", strings.TrimSuffix(format, "-"+ext), ext)
+	f, err := createFile(dir, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := password.WriteManager(f, format, logins); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	log.Noticef("output to file %s success", outputPath(dir, filename))
+	return []string{filename}, nil
 }
 
 func (d *Data) addSource(Sources []item.Item) {
@@ -79,10 +206,22 @@ func (d *Data) addSource(Sources []item.Item) {
 			d.sources[source] = &password.ChromiumPassword{}
 		case item.ChromiumCookie:
 			d.sources[source] = &cookie.ChromiumCookie{}
+		case item.ChromiumExtensionCookie:
+			d.sources[source] = &cookie.ChromiumExtensionCookie{}
 		case item.ChromiumBookmark:
 			d.sources[source] = &bookmark.ChromiumBookmark{}
+		case item.ChromiumBookmarkChange:
+			d.sources[source] = &bookmark.ChromiumBookmarkChange{}
 		case item.ChromiumHistory:
 			d.sources[source] = &history.ChromiumHistory{}
+		case item.ChromiumVisit:
+			d.sources[source] = &history.ChromiumVisit{}
+		case item.ChromiumSearchTerm:
+			d.sources[source] = &search.ChromiumSearchTerm{}
+		case item.ChromiumShortcut:
+			d.sources[source] = &search.ChromiumShortcut{}
+		case item.ChromiumSearch:
+			d.sources[source] = &search.ChromiumSearch{}
 		case item.ChromiumDownload:
 			d.sources[source] = &download.ChromiumDownload{}
 		case item.ChromiumCreditCard:
@@ -103,6 +242,14 @@ func (d *Data) addSource(Sources []item.Item) {
 			d.sources[source] = &bookmark.FirefoxBookmark{}
 		case item.FirefoxHistory:
 			d.sources[source] = &history.FirefoxHistory{}
+		case item.FirefoxVisit:
+			d.sources[source] = &history.FirefoxVisit{}
+		case item.FirefoxInputHistory:
+			d.sources[source] = &history.FirefoxInputHistory{}
+		case item.FirefoxOrigin:
+			d.sources[source] = &history.FirefoxOrigin{}
+		case item.FirefoxSearch:
+			d.sources[source] = &search.FirefoxSearch{}
 		case item.FirefoxDownload:
 			d.sources[source] = &download.FirefoxDownload{}
 		case item.FirefoxLocalStorage:
@@ -0,0 +1,68 @@
+package browingdata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hack-browser-data/internal/browingdata/bookmark"
+	"hack-browser-data/internal/item"
+)
+
+func TestMergeBookmarks(t *testing.T) {
+	t.Parallel()
+
+	chrome, edge := New("Chrome", "Default", nil), New("Microsoft Edge", "Profile 1", nil)
+	chromeBookmarks, edgeBookmarks := &bookmark.ChromiumBookmark{}, &bookmark.ChromiumBookmark{}
+	testRecords(t, chromeBookmarks, `[{"id":1,"name":"Bookmarks bar","type":"folder","root":"bookmark_bar"},
+		{"id":2,"parent_id":1,"name":"GitHub","type":"url","url":"https://github.com","date_added":"2022-01-02T03:04:05Z"}]`)
+	testRecords(t, edgeBookmarks, `[{"id":1,"name":"Favorites bar","type":"folder","root":"bookmark_bar"},
+		{"id":2,"parent_id":1,"name":"Go","type":"url","url":"https://go.dev"}]`)
+	chrome.sources[item.ChromiumBookmark] = chromeBookmarks
+	edge.sources[item.ChromiumBookmark] = edgeBookmarks
+	// browsers without bookmarks have no folder
+	firefox := New("Firefox", "default-release", nil)
+	firefox.sources[item.FirefoxBookmark] = &bookmark.FirefoxBookmark{}
+
+	dir := t.TempDir()
+	files, err := MergeBookmarks(dir, map[string]*Data{
+		"microsoft_edge_profile_1": edge,
+		"chrome_default":           chrome,
+		"firefox_default_release":  firefox,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != mergedBookmarkFile {
+		t.Fatalf("got files %v, want %s", files, mergedBookmarkFile)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, mergedBookmarkFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	if strings.Count(got, "<!DOCTYPE NETSCAPE-Bookmark-file-1>") != 1 {
+		t.Error("got merged file without a single Netscape header")
+	}
+	if strings.Contains(got, "firefox_default_release") {
+		t.Error("got folder of browser without bookmarks")
+	}
+	// folders of browsers are sorted by name, and contain the roots of browsers
+	want := []string{
+		`    <DT><H3>chrome_default</H3>`,
+		`        <DT><H3>Bookmarks bar</H3>`,
+		`            <DT><A HREF="https://github.com" ADD_DATE="1641092645">GitHub</A>`,
+		`    <DT><H3>microsoft_edge_profile_1</H3>`,
+		`        <DT><H3>Favorites bar</H3>`,
+		`            <DT><A HREF="https://go.dev">Go</A>`,
+	}
+	last := -1
+	for _, line := range want {
+		i := strings.Index(got, line+"\n")
+		if i <= last {
+			t.Fatalf("got merged bookmarks without %q after the previous line:\n%s", line, got)
+		}
+		last = i
+	}
+}
@@ -1,74 +1,175 @@
 package cookie
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"database/sql"
+	"fmt"
+	"net/url"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"hack-browser-data/internal/decrypter"
 	"hack-browser-data/internal/item"
 	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/fileutil"
 	"hack-browser-data/internal/utils/typeutil"
 
 	// import sqlite3 driver
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/tidwall/gjson"
 )
 
 type ChromiumCookie []cookie
 
 type cookie struct {
-	Host         string
-	Path         string
-	KeyName      string
-	encryptValue []byte
-	Value        string
-	IsSecure     bool
-	IsHTTPOnly   bool
-	HasExpire    bool
-	IsPersistent bool
-	CreateDate   time.Time
-	ExpireDate   time.Time
+	Host              string `json:"host" csv:"host"`
+	Path              string `json:"path" csv:"path"`
+	KeyName           string `json:"key_name" csv:"key_name"`
+	encryptValue      []byte
+	Value             string    `json:"value" csv:"value"`
+	IsSecure          bool      `json:"is_secure" csv:"is_secure"`
+	IsHTTPOnly        bool      `json:"is_http_only" csv:"is_http_only"`
+	HasExpire         bool      `json:"has_expire" csv:"has_expire"`
+	IsPersistent      bool      `json:"is_persistent" csv:"is_persistent"`
+	SameSite          string    `json:"same_site" csv:"same_site"`
+	RawSameSite       string    `json:"raw_same_site" csv:"raw_same_site"`
+	Priority          string    `json:"priority" csv:"priority"`
+	SourceScheme      string    `json:"source_scheme" csv:"source_scheme"`
+	SourcePort        int       `json:"source_port" csv:"source_port"`
+	SchemeMap         string    `json:"scheme_map" csv:"scheme_map"`
+	PartitionKey      string    `json:"partition_key" csv:"partition_key"`
+	UserContextID     int       `json:"user_context_id" csv:"user_context_id"`
+	ContainerName     string    `json:"container_name" csv:"container_name"`
+	PrivateBrowsingID int       `json:"private_browsing_id" csv:"private_browsing_id"`
+	FirstPartyDomain  string    `json:"first_party_domain" csv:"first_party_domain"`
+	CreateDate        time.Time `json:"create_date" csv:"create_date"`
+	ExpireDate        time.Time `json:"expire_date" csv:"expire_date"`
+	LastAccessDate    time.Time `json:"last_access_date" csv:"last_access_date"`
+	LastUpdateDate    time.Time `json:"last_update_date" csv:"last_update_date"`
+	// Provenance is the row of cookie
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
-	queryChromiumCookie = `SELECT name, encrypted_value, host_key, path, creation_utc, expires_utc, is_secure, is_httponly, has_expires, is_persistent FROM cookies`
+	queryChromiumCookie = `SELECT rowid, name, encrypted_value, host_key, path, creation_utc, expires_utc, is_secure, is_httponly, has_expires, is_persistent, %s FROM cookies`
+	queryChromiumMeta   = `SELECT value FROM meta WHERE key = 'version'`
+	queryTableColumns   = `PRAGMA table_info(%s)`
+)
+
+// chromiumOptionalColumns are added by newer versions of Chromium
+var chromiumOptionalColumns = []optionalColumn{
+	{"samesite", "-1"},
+	{"priority", "1"},
+	{"source_scheme", "0"},
+	{"source_port", "-1"},
+	{"last_access_utc", "0"},
+	{"last_update_utc", "0"},
+	{"top_frame_site_key", "''"},
+}
+
+// since cookie database meta version 24, chromium prepends SHA-256 of the host_key to the plaintext value
+const (
+	domainHashVersion = 24
+	domainHashLength  = sha256.Size
 )
 
 func (c *ChromiumCookie) Parse(masterKey []byte) error {
-	cookieDB, err := sql.Open("sqlite3", item.TempChromiumCookie)
+	cookies, err := parseChromiumCookie(item.TempChromiumCookie, masterKey)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(item.TempChromiumCookie)
-	defer cookieDB.Close()
-	rows, err := cookieDB.Query(queryChromiumCookie)
+	*c = cookies
+	return nil
+}
+
+func (c *ChromiumCookie) Name() string {
+	return "cookie"
+}
+
+func (c *ChromiumCookie) Length() int {
+	return len(*c)
+}
+
+// ChromiumExtensionCookie is the cookies of chrome extensions, which stored in the Extension Cookies database
+type ChromiumExtensionCookie []cookie
+
+func (c *ChromiumExtensionCookie) Parse(masterKey []byte) error {
+	cookies, err := parseChromiumCookie(item.TempChromiumExtensionCookie, masterKey)
 	if err != nil {
 		return err
 	}
+	*c = cookies
+	return nil
+}
+
+func (c *ChromiumExtensionCookie) Name() string {
+	return "extensionCookie"
+}
+
+func (c *ChromiumExtensionCookie) Length() int {
+	return len(*c)
+}
+
+func parseChromiumCookie(filename string, masterKey []byte) ([]cookie, error) {
+	cookieDB, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(filename)
+	defer cookieDB.Close()
+
+	var version int
+	if err := cookieDB.QueryRow(queryChromiumMeta).Scan(&version); err != nil {
+		log.Debugf("query cookie meta version error %s", err)
+	}
+	optional, err := selectOptionalColumns(cookieDB, "cookies", chromiumOptionalColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cookieDB.Query(fmt.Sprintf(queryChromiumCookie, optional))
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
+	var cookies []cookie
 	for rows.Next() {
 		var (
-			key, host, path                               string
+			rowid                                         int64
+			key, host, path, partitionKey                 string
 			isSecure, isHTTPOnly, hasExpire, isPersistent int
+			sameSite, priority, sourceScheme, sourcePort  int
 			createDate, expireDate                        int64
+			lastAccessDate, lastUpdateDate                int64
 			value, encryptValue                           []byte
 		)
-		if err = rows.Scan(&key, &encryptValue, &host, &path, &createDate, &expireDate, &isSecure, &isHTTPOnly, &hasExpire, &isPersistent); err != nil {
+		if err = rows.Scan(&rowid, &key, &encryptValue, &host, &path, &createDate, &expireDate, &isSecure, &isHTTPOnly, &hasExpire, &isPersistent,
+			&sameSite, &priority, &sourceScheme, &sourcePort, &lastAccessDate, &lastUpdateDate, &partitionKey); err != nil {
 			log.Warn(err)
 		}
 
 		cookie := cookie{
-			KeyName:      key,
-			Host:         host,
-			Path:         path,
-			encryptValue: encryptValue,
-			IsSecure:     typeutil.IntToBool(isSecure),
-			IsHTTPOnly:   typeutil.IntToBool(isHTTPOnly),
-			HasExpire:    typeutil.IntToBool(hasExpire),
-			IsPersistent: typeutil.IntToBool(isPersistent),
-			CreateDate:   typeutil.TimeEpoch(createDate),
-			ExpireDate:   typeutil.TimeEpoch(expireDate),
+			KeyName:        key,
+			Host:           host,
+			Path:           path,
+			encryptValue:   encryptValue,
+			IsSecure:       typeutil.IntToBool(isSecure),
+			IsHTTPOnly:     typeutil.IntToBool(isHTTPOnly),
+			HasExpire:      typeutil.IntToBool(hasExpire),
+			IsPersistent:   typeutil.IntToBool(isPersistent),
+			SameSite:       chromiumSameSite(sameSite),
+			Priority:       chromiumPriority(priority),
+			SourceScheme:   chromiumSourceScheme(sourceScheme),
+			SourcePort:     sourcePort,
+			PartitionKey:   partitionKey,
+			CreateDate:     typeutil.TimeEpoch(createDate),
+			ExpireDate:     typeutil.TimeEpoch(expireDate),
+			LastAccessDate: typeutil.TimeEpoch(lastAccessDate),
+			LastUpdateDate: typeutil.TimeEpoch(lastUpdateDate),
+			Provenance:     item.RowProvenance("cookies", rowid),
 		}
 		if len(encryptValue) > 0 {
 			var err error
@@ -81,29 +182,84 @@ func (c *ChromiumCookie) Parse(masterKey []byte) error {
 				log.Error(err)
 			}
 		}
+		if version >= domainHashVersion && len(value) > 0 {
+			var ok bool
+			if value, ok = stripDomainHash(host, value); !ok {
+				log.Warnf("cookie %s of %s doesn't start with domain hash", key, host)
+			}
+		}
 		cookie.Value = string(value)
-		*c = append(*c, cookie)
+		cookies = append(cookies, cookie)
 	}
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].CreateDate.After((*c)[j].CreateDate)
+	sort.Slice(cookies, func(i, j int) bool {
+		return cookies[i].CreateDate.After(cookies[j].CreateDate)
 	})
-	return nil
+	return cookies, nil
 }
 
-func (c *ChromiumCookie) Name() string {
-	return "cookie"
+// stripDomainHash removes the SHA-256 of host from the decrypted value,
+// the value is returned as is if it doesn't start with the hash of host.
+func stripDomainHash(host string, value []byte) ([]byte, bool) {
+	if len(value) < domainHashLength {
+		return value, false
+	}
+	hash := sha256.Sum256([]byte(host))
+	if !bytes.Equal(value[:domainHashLength], hash[:]) {
+		return value, false
+	}
+	return value[domainHashLength:], true
 }
 
-func (c *ChromiumCookie) Length() int {
-	return len(*c)
+func chromiumSameSite(a int) string {
+	switch a {
+	case 0:
+		return "no_restriction"
+	case 1:
+		return "lax"
+	case 2:
+		return "strict"
+	default:
+		return "unspecified"
+	}
+}
+
+func chromiumPriority(a int) string {
+	switch a {
+	case 0:
+		return "low"
+	case 2:
+		return "high"
+	default:
+		return "medium"
+	}
+}
+
+func chromiumSourceScheme(a int) string {
+	switch a {
+	case 1:
+		return "non_secure"
+	case 2:
+		return "secure"
+	default:
+		return "unset"
+	}
 }
 
 type FirefoxCookie []cookie
 
 const (
-	queryFirefoxCookie = `SELECT name, value, host, path, creationTime, expiry, isSecure, isHttpOnly FROM moz_cookies`
+	queryFirefoxCookie = `SELECT rowid, name, value, host, path, creationTime, expiry, isSecure, isHttpOnly, %s FROM moz_cookies`
 )
 
+// firefoxOptionalColumns are added by newer versions of Firefox
+var firefoxOptionalColumns = []optionalColumn{
+	{"sameSite", "-1"},
+	{"rawSameSite", "-1"},
+	{"schemeMap", "0"},
+	{"lastAccessed", "0"},
+	{"originAttributes", "''"},
+}
+
 func (f *FirefoxCookie) Parse(masterKey []byte) error {
 	cookieDB, err := sql.Open("sqlite3", item.TempFirefoxCookie)
 	if err != nil {
@@ -111,29 +267,51 @@ func (f *FirefoxCookie) Parse(masterKey []byte) error {
 	}
 	defer os.Remove(item.TempFirefoxCookie)
 	defer cookieDB.Close()
-	rows, err := cookieDB.Query(queryFirefoxCookie)
+	containers, err := firefoxContainers(item.TempFirefoxContainer)
+	if err != nil {
+		log.Debugf("read firefox containers error %s", err)
+	}
+	optional, err := selectOptionalColumns(cookieDB, "moz_cookies", firefoxOptionalColumns)
+	if err != nil {
+		return err
+	}
+	rows, err := cookieDB.Query(fmt.Sprintf(queryFirefoxCookie, optional))
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			name, value, host, path string
-			isSecure, isHTTPOnly    int
-			creationTime, expiry    int64
+			rowid                                     int64
+			name, value, host, path, originAttributes string
+			isSecure, isHTTPOnly                      int
+			sameSite, rawSameSite, schemeMap          int
+			creationTime, expiry, lastAccessed        int64
 		)
-		if err = rows.Scan(&name, &value, &host, &path, &creationTime, &expiry, &isSecure, &isHTTPOnly); err != nil {
+		if err = rows.Scan(&rowid, &name, &value, &host, &path, &creationTime, &expiry, &isSecure, &isHTTPOnly,
+			&sameSite, &rawSameSite, &schemeMap, &lastAccessed, &originAttributes); err != nil {
 			log.Warn(err)
 		}
+		attrs := parseOriginAttributes(originAttributes)
 		*f = append(*f, cookie{
-			KeyName:    name,
-			Host:       host,
-			Path:       path,
-			IsSecure:   typeutil.IntToBool(isSecure),
-			IsHTTPOnly: typeutil.IntToBool(isHTTPOnly),
-			CreateDate: typeutil.TimeStamp(creationTime / 1000000),
-			ExpireDate: typeutil.TimeStamp(expiry),
-			Value:      value,
+			KeyName:           name,
+			Host:              host,
+			Path:              path,
+			IsSecure:          typeutil.IntToBool(isSecure),
+			IsHTTPOnly:        typeutil.IntToBool(isHTTPOnly),
+			SameSite:          firefoxSameSite(sameSite),
+			RawSameSite:       firefoxSameSite(rawSameSite),
+			SchemeMap:         firefoxSchemeMap(schemeMap),
+			PartitionKey:      attrs.partitionKey,
+			UserContextID:     attrs.userContextID,
+			ContainerName:     containers[attrs.userContextID],
+			PrivateBrowsingID: attrs.privateBrowsingID,
+			FirstPartyDomain:  attrs.firstPartyDomain,
+			CreateDate:        typeutil.TimeStamp(creationTime / 1000000),
+			ExpireDate:        typeutil.TimeStamp(expiry),
+			LastAccessDate:    typeutil.TimeStamp(lastAccessed / 1000000),
+			Value:             value,
+			Provenance:        item.RowProvenance("moz_cookies", rowid),
 		})
 	}
 	return nil
@@ -146,3 +324,131 @@ func (f *FirefoxCookie) Name() string {
 func (f *FirefoxCookie) Length() int {
 	return len(*f)
 }
+
+func firefoxSameSite(a int) string {
+	switch a {
+	case 0:
+		return "no_restriction"
+	case 1:
+		return "lax"
+	case 2:
+		return "strict"
+	default:
+		return "unspecified"
+	}
+}
+
+// firefoxSchemeMap converts the bitmap of schemes which have set the cookie to string, e.g. http|https
+func firefoxSchemeMap(a int) string {
+	schemes := []struct {
+		bit  int
+		name string
+	}{
+		{1, "http"},
+		{2, "https"},
+		{4, "file"},
+	}
+	var s []string
+	for _, v := range schemes {
+		if a&v.bit != 0 {
+			s = append(s, v.name)
+		}
+	}
+	return strings.Join(s, "|")
+}
+
+type originAttributes struct {
+	userContextID     int
+	privateBrowsingID int
+	firstPartyDomain  string
+	partitionKey      string
+}
+
+// parseOriginAttributes parses the origin attributes suffix of firefox,
+// e.g. ^userContextId=1&privateBrowsingId=1&partitionKey=%28https%2Cexample.com%29
+func parseOriginAttributes(s string) originAttributes {
+	var attrs originAttributes
+	values, err := url.ParseQuery(strings.TrimPrefix(s, "^"))
+	if err != nil {
+		return attrs
+	}
+	attrs.userContextID, _ = strconv.Atoi(values.Get("userContextId"))
+	attrs.privateBrowsingID, _ = strconv.Atoi(values.Get("privateBrowsingId"))
+	attrs.firstPartyDomain = values.Get("firstPartyDomain")
+	attrs.partitionKey = values.Get("partitionKey")
+	return attrs
+}
+
+// firefoxContainers returns the names of Multi-Account Containers keyed by userContextId,
+// default containers have no name but a localization id.
+func firefoxContainers(filename string) (map[int]string, error) {
+	s, err := fileutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	l10nNames := map[string]string{
+		"userContextPersonal.label": "Personal",
+		"userContextWork.label":     "Work",
+		"userContextBanking.label":  "Banking",
+		"userContextShopping.label": "Shopping",
+	}
+	containers := make(map[int]string)
+	for _, v := range gjson.Get(s, "identities").Array() {
+		name := v.Get("name").String()
+		if name == "" {
+			l10nID := v.Get("l10nID").String()
+			if n, ok := l10nNames[l10nID]; ok {
+				name = n
+			} else {
+				name = l10nID
+			}
+		}
+		containers[int(v.Get("userContextId").Int())] = name
+	}
+	return containers, nil
+}
+
+type optionalColumn struct {
+	name     string
+	fallback string
+}
+
+// selectOptionalColumns returns the select expression of columns which only exist in the databases
+// of newer browser versions, the fallback value is selected instead of missing columns.
+func selectOptionalColumns(db *sql.DB, table string, optional []optionalColumn) (string, error) {
+	columns, err := tableColumns(db, table)
+	if err != nil {
+		return "", err
+	}
+	s := make([]string, 0, len(optional))
+	for _, v := range optional {
+		if columns[v.name] {
+			s = append(s, v.name)
+		} else {
+			s = append(s, v.fallback)
+		}
+	}
+	return strings.Join(s, ", "), nil
+}
+
+// tableColumns returns the column names of table
+func tableColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(fmt.Sprintf(queryTableColumns, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, cType      string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &cType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,211 @@
+package cookie
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+var testMasterKey = []byte("0123456789abcdef")
+
+// encryptValue encrypts value with testMasterKey as chromium does on the current os
+func encryptValue(t *testing.T, value []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS == "windows" {
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		return gcm.Seal(append([]byte("v10"), nonce...), nonce, value, nil)
+	}
+	padding := aes.BlockSize - len(value)%aes.BlockSize
+	src := append(value, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	dst := make([]byte, len(src))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(dst, src)
+	return append([]byte("v10"), dst...)
+}
+
+// createDB creates the database of filename with stmts, which is removed by the source after parsing
+func createDB(t *testing.T, filename string, stmts []string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStripDomainHash(t *testing.T) {
+	t.Parallel()
+
+	hash := sha256.Sum256([]byte(".github.com"))
+	testCases := []struct {
+		host  string
+		value []byte
+		want  string
+		ok    bool
+	}{
+		{".github.com", append(hash[:], []byte("value")...), "value", true},
+		{".example.com", append(hash[:], []byte("value")...), string(hash[:]) + "value", false},
+		{".github.com", []byte("short"), "short", false},
+	}
+	for _, tc := range testCases {
+		got, ok := stripDomainHash(tc.host, tc.value)
+		if string(got) != tc.want || ok != tc.ok {
+			t.Errorf("stripDomainHash(%s) = %q, %v, want %q, %v", tc.host, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseOriginAttributes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		s    string
+		want originAttributes
+	}{
+		{"", originAttributes{}},
+		{"^userContextId=2", originAttributes{userContextID: 2}},
+		{"^privateBrowsingId=1&firstPartyDomain=example.com", originAttributes{privateBrowsingID: 1, firstPartyDomain: "example.com"}},
+		{"^partitionKey=%28https%2Cexample.com%29", originAttributes{partitionKey: "(https,example.com)"}},
+	}
+	for _, tc := range testCases {
+		if got := parseOriginAttributes(tc.s); got != tc.want {
+			t.Errorf("parseOriginAttributes(%s) = %+v, want %+v", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestWriteNetscape(t *testing.T) {
+	t.Parallel()
+
+	cookies := []cookie{
+		{Host: ".github.com", Path: "/", KeyName: "logged_in", Value: "yes", IsSecure: true, IsHTTPOnly: true, ExpireDate: time.Unix(1700000000, 0)},
+		{Host: "gist.github.com", Path: "/", KeyName: "session", Value: "1", ExpireDate: typeutil.TimeEpoch(0)},
+		{Host: ".example.com", Path: "/", KeyName: "other", Value: "2"},
+	}
+	var b strings.Builder
+	if err := writeNetscape(&b, cookies, []string{"github.com"}); err != nil {
+		t.Fatal(err)
+	}
+	want := netscapeHeader +
+		"#HttpOnly_.github.com\tTRUE\t/\tTRUE\t1700000000\tlogged_in\tyes\n" +
+		"gist.github.com\tFALSE\t/\tFALSE\t0\tsession\t1\n"
+	if b.String() != want {
+		t.Errorf("writeNetscape() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestChromiumCookie(t *testing.T) {
+	t.Parallel()
+
+	hash := sha256.Sum256([]byte(".github.com"))
+	createDB(t, item.TempChromiumCookie, []string{
+		"CREATE TABLE meta (key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)",
+		"INSERT INTO meta VALUES ('version', '24')",
+		`CREATE TABLE cookies (creation_utc INTEGER, host_key TEXT, top_frame_site_key TEXT, name TEXT, value TEXT,
+			encrypted_value BLOB, path TEXT, expires_utc INTEGER, is_secure INTEGER, is_httponly INTEGER, last_access_utc INTEGER,
+			has_expires INTEGER, is_persistent INTEGER, priority INTEGER, samesite INTEGER, source_scheme INTEGER,
+			source_port INTEGER, last_update_utc INTEGER)`,
+		// values of meta version 24 start with the SHA-256 of host_key, CHIPS cookies have the top frame site
+		fmt.Sprintf(`INSERT INTO cookies VALUES (13285566245000000, '.github.com', 'https://example.com', 'logged_in', '',
+			X'%x', '/', 13317102245000000, 1, 1, 13285566245000000, 1, 1, 2, 0, 2, 443, 13285566245000000)`,
+			encryptValue(t, append(hash[:], "yes"...))),
+	})
+	cookies := &ChromiumCookie{}
+	if err := cookies.Parse(testMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if len(*cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(*cookies))
+	}
+	got := (*cookies)[0]
+	if got.Value != "yes" {
+		t.Errorf("got value %q, want yes without domain hash", got.Value)
+	}
+	if got.PartitionKey != "https://example.com" {
+		t.Errorf("got partition key %q, want https://example.com", got.PartitionKey)
+	}
+	if got.SameSite != "no_restriction" || got.Priority != "high" || got.SourceScheme != "secure" || got.SourcePort != 443 {
+		t.Errorf("got same site %s priority %s source %s:%d", got.SameSite, got.Priority, got.SourceScheme, got.SourcePort)
+	}
+	if !got.LastAccessDate.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("got last access date %s, want 2022-01-02T03:04:05Z", got.LastAccessDate)
+	}
+	if got.Provenance == nil || got.Provenance.Table != "cookies" || got.Provenance.RowID != 1 {
+		t.Errorf("got provenance %+v, want row 1 of cookies", got.Provenance)
+	}
+}
+
+func TestChromiumCookieOldSchema(t *testing.T) {
+	t.Parallel()
+
+	// databases before meta version 24 have no domain hash, and none of the optional columns
+	createDB(t, item.TempChromiumExtensionCookie, []string{
+		"CREATE TABLE meta (key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)",
+		"INSERT INTO meta VALUES ('version', '12')",
+		`CREATE TABLE cookies (creation_utc INTEGER, host_key TEXT, name TEXT, value TEXT, path TEXT, expires_utc INTEGER,
+			is_secure INTEGER, is_httponly INTEGER, has_expires INTEGER, is_persistent INTEGER, encrypted_value BLOB)`,
+		fmt.Sprintf(`INSERT INTO cookies VALUES (13285566245000000, 'example.com', 'session', '', '/', 0, 0, 0, 0, 0, X'%x')`,
+			encryptValue(t, []byte("value-of-session-cookie-longer-than-hash"))),
+	})
+	cookies := &ChromiumExtensionCookie{}
+	if err := cookies.Parse(testMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if len(*cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(*cookies))
+	}
+	got := (*cookies)[0]
+	if got.Value != "value-of-session-cookie-longer-than-hash" {
+		t.Errorf("got value %q", got.Value)
+	}
+	if got.SameSite != "unspecified" || got.Priority != "medium" || got.SourceScheme != "unset" || got.SourcePort != -1 || got.PartitionKey != "" {
+		t.Errorf("got same site %s priority %s source %s:%d partition key %q, want fallbacks",
+			got.SameSite, got.Priority, got.SourceScheme, got.SourcePort, got.PartitionKey)
+	}
+	if !got.LastAccessDate.Equal(typeutil.TimeEpoch(0)) || !got.LastUpdateDate.Equal(typeutil.TimeEpoch(0)) {
+		t.Errorf("got last access date %s and last update date %s, want the epoch", got.LastAccessDate, got.LastUpdateDate)
+	}
+}
+
+func TestSelectOptionalColumns(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "Cookies"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE cookies (host_key TEXT, samesite INTEGER, source_port INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := selectOptionalColumns(db, "cookies", chromiumOptionalColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "samesite, 1, 0, source_port, 0, 0, ''"; got != want {
+		t.Errorf("selectOptionalColumns() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package cookie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const netscapeHeader = `# Netscape HTTP Cookie File
+# https://curl.se/docs/http-cookies.html
+# This file was generated by hack-browser-data! Edit at your own risk.
+
+`
+
+// httpOnlyPrefix is prepended to the domain of HttpOnly cookies, which is supported by curl and wget
+const httpOnlyPrefix = "#HttpOnly_"
+
+func (c *ChromiumCookie) WriteCookieJar(w io.Writer, domains []string) error {
+	return writeNetscape(w, *c, domains)
+}
+
+func (c *ChromiumExtensionCookie) WriteCookieJar(w io.Writer, domains []string) error {
+	return writeNetscape(w, *c, domains)
+}
+
+func (f *FirefoxCookie) WriteCookieJar(w io.Writer, domains []string) error {
+	return writeNetscape(w, *f, domains)
+}
+
+// writeNetscape writes cookies in the Netscape cookies.txt format used by curl, wget and yt-dlp,
+// only cookies of domains and their subdomains are written if domains is not empty.
+func writeNetscape(w io.Writer, cookies []cookie, domains []string) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(netscapeHeader); err != nil {
+		return err
+	}
+	for _, c := range cookies {
+		if len(domains) > 0 && !matchDomain(c.Host, domains) {
+			continue
+		}
+		domain := c.Host
+		if c.IsHTTPOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		// session cookies of chromium expire at 1601, which are written as 0
+		expiry := c.ExpireDate.Unix()
+		if expiry < 0 {
+			expiry = 0
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, netscapeBool(strings.HasPrefix(c.Host, ".")), c.Path, netscapeBool(c.IsSecure), expiry, c.KeyName, c.Value)
+	}
+	return bw.Flush()
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// matchDomain reports whether host is one of domains or their subdomains
+func matchDomain(host string, domains []string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), ".")
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(d), ".")
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,20 +12,23 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-type ChromiumCreditCard []card
+type ChromiumCreditCard []Card
 
-type card struct {
-	GUID            string
-	Name            string
-	ExpirationYear  string
-	ExpirationMonth string
-	CardNumber      string
-	Address         string
-	NickName        string
+// Card is a decrypted credit card of browser
+type Card struct {
+	GUID            string `json:"guid" csv:"guid"`
+	Name            string `json:"name" csv:"name"`
+	ExpirationYear  string `json:"expiration_year" csv:"expiration_year"`
+	ExpirationMonth string `json:"expiration_month" csv:"expiration_month"`
+	CardNumber      string `json:"card_number" csv:"card_number"`
+	Address         string `json:"address" csv:"address"`
+	NickName        string `json:"nick_name" csv:"nick_name"`
+	// Provenance is the row of credit card
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
-	queryChromiumCredit = `SELECT guid, name_on_card, expiration_month, expiration_year, card_number_encrypted, billing_address_id, nickname FROM credit_cards`
+	queryChromiumCredit = `SELECT rowid, guid, name_on_card, expiration_month, expiration_year, card_number_encrypted, billing_address_id, nickname FROM credit_cards`
 )
 
 func (c *ChromiumCreditCard) Parse(masterKey []byte) error {
@@ -42,19 +45,21 @@ func (c *ChromiumCreditCard) Parse(masterKey []byte) error {
 	defer rows.Close()
 	for rows.Next() {
 		var (
+			rowid                                      int64
 			name, month, year, guid, address, nickname string
 			value, encryptValue                        []byte
 		)
-		if err := rows.Scan(&guid, &name, &month, &year, &encryptValue, &address, &nickname); err != nil {
+		if err := rows.Scan(&rowid, &guid, &name, &month, &year, &encryptValue, &address, &nickname); err != nil {
 			log.Warn(err)
 		}
-		ccInfo := card{
+		ccInfo := Card{
 			GUID:            guid,
 			Name:            name,
 			ExpirationMonth: month,
 			ExpirationYear:  year,
 			Address:         address,
 			NickName:        nickname,
+			Provenance:      item.RowProvenance("credit_cards", rowid),
 		}
 		if masterKey == nil {
 			value, err = decrypter.DPAPI(encryptValue)
@@ -81,7 +86,7 @@ func (c *ChromiumCreditCard) Length() int {
 	return len(*c)
 }
 
-type YandexCreditCard []card
+type YandexCreditCard []Card
 
 func (c *YandexCreditCard) Parse(masterKey []byte) error {
 	creditDB, err := sql.Open("sqlite3", item.TempYandexCreditCard)
@@ -98,19 +103,21 @@ func (c *YandexCreditCard) Parse(masterKey []byte) error {
 	defer rows.Close()
 	for rows.Next() {
 		var (
+			rowid                                      int64
 			name, month, year, guid, address, nickname string
 			value, encryptValue                        []byte
 		)
-		if err := rows.Scan(&guid, &name, &month, &year, &encryptValue, &address, &nickname); err != nil {
+		if err := rows.Scan(&rowid, &guid, &name, &month, &year, &encryptValue, &address, &nickname); err != nil {
 			log.Warn(err)
 		}
-		ccInfo := card{
+		ccInfo := Card{
 			GUID:            guid,
 			Name:            name,
 			ExpirationMonth: month,
 			ExpirationYear:  year,
 			Address:         address,
 			NickName:        nickname,
+			Provenance:      item.RowProvenance("credit_cards", rowid),
 		}
 		if masterKey == nil {
 			value, err = decrypter.DPAPI(encryptValue)
@@ -136,3 +143,11 @@ func (c *YandexCreditCard) Name() string {
 func (c *YandexCreditCard) Length() int {
 	return len(*c)
 }
+
+func (c *ChromiumCreditCard) Cards() []Card {
+	return *c
+}
+
+func (c *YandexCreditCard) Cards() []Card {
+	return *c
+}
@@ -19,16 +19,18 @@ import (
 type ChromiumDownload []download
 
 type download struct {
-	TargetPath string
-	URL        string
-	TotalBytes int64
-	StartTime  time.Time
-	EndTime    time.Time
-	MimeType   string
+	TargetPath string    `json:"target_path" csv:"target_path"`
+	URL        string    `json:"url" csv:"url"`
+	TotalBytes int64     `json:"total_bytes" csv:"total_bytes"`
+	StartTime  time.Time `json:"start_time" csv:"start_time"`
+	EndTime    time.Time `json:"end_time" csv:"end_time"`
+	MimeType   string    `json:"mime_type" csv:"mime_type"`
+	// Provenance is the row of download, which is the place of download annotations in Firefox
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
-	queryChromiumDownload = `SELECT target_path, tab_url, total_bytes, start_time, end_time, mime_type FROM downloads`
+	queryChromiumDownload = `SELECT rowid, target_path, tab_url, total_bytes, start_time, end_time, mime_type FROM downloads`
 )
 
 func (c *ChromiumDownload) Parse(masterKey []byte) error {
@@ -45,10 +47,10 @@ func (c *ChromiumDownload) Parse(masterKey []byte) error {
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			targetPath, tabURL, mimeType   string
-			totalBytes, startTime, endTime int64
+			targetPath, tabURL, mimeType       string
+			id, totalBytes, startTime, endTime int64
 		)
-		if err := rows.Scan(&targetPath, &tabURL, &totalBytes, &startTime, &endTime, &mimeType); err != nil {
+		if err := rows.Scan(&id, &targetPath, &tabURL, &totalBytes, &startTime, &endTime, &mimeType); err != nil {
 			log.Warn(err)
 		}
 		data := download{
@@ -58,6 +60,7 @@ func (c *ChromiumDownload) Parse(masterKey []byte) error {
 			StartTime:  typeutil.TimeEpoch(startTime),
 			EndTime:    typeutil.TimeEpoch(endTime),
 			MimeType:   mimeType,
+			Provenance: item.RowProvenance("downloads", id),
 		}
 		*c = append(*c, data)
 	}
@@ -124,6 +127,7 @@ func (f *FirefoxDownload) Parse(masterKey []byte) error {
 				TotalBytes: fileSize.Int(),
 				StartTime:  typeutil.TimeStamp(dateAdded / 1000000),
 				EndTime:    typeutil.TimeStamp(endTime.Int() / 1000),
+				Provenance: item.RowProvenance("moz_places", placeID),
 			})
 		}
 	}
@@ -0,0 +1,251 @@
+package browingdata
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"os/user"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+const (
+	ecsVersion = "8.11.0"
+	// ecsNamespace is the custom field set of ECS documents, which keeps the original record
+	ecsNamespace = "hack_browser_data"
+	// ecsAction is the action line of Elastic bulk API before each document
+	ecsAction = `{"create":{}}`
+)
+
+func init() {
+	RegisterOutputter("ecs", func(options OutputOptions) (Outputter, error) {
+		e := newECSWriter()
+		return &fileOutputter{ext: "ndjson", write: e.write, supports: func(source Source) bool {
+			_, _, ok := sourceRecords(source)
+			return ok
+		}}, nil
+	})
+}
+
+// ecsCategories are the event category and type of artifacts, other artifacts are web info.
+// Types must be allowed by the category in ECS, e.g. web only allows access, error and info.
+var ecsCategories = map[string][2]string{
+	"history":         {"web", "access"},
+	"visit":           {"web", "access"},
+	"inputHistory":    {"web", "access"},
+	"origin":          {"web", "access"},
+	"search":          {"web", "access"},
+	"searchTerm":      {"web", "access"},
+	"shortcut":        {"web", "access"},
+	"download":        {"file", "creation"},
+	"password":        {"authentication", "info"},
+	"extension":       {"package", "info"},
+	"bookmarkChange":  {"configuration", "change"},
+	"cookie":          {"web", "info"},
+	"extensionCookie": {"web", "info"},
+}
+
+// ecsWriter writes records as Elastic Common Schema documents in the NDJSON format of Elastic bulk API,
+// host and user of documents are the machine and user the browsers are read from.
+type ecsWriter struct {
+	host    map[string]interface{}
+	user    map[string]interface{}
+	created time.Time
+}
+
+func newECSWriter() *ecsWriter {
+	hostname, _ := os.Hostname()
+	osType := runtime.GOOS
+	if osType == "darwin" {
+		osType = "macos"
+	}
+	e := &ecsWriter{
+		host: map[string]interface{}{
+			"hostname":     hostname,
+			"name":         hostname,
+			"architecture": runtime.GOARCH,
+			"os":           map[string]interface{}{"type": osType, "platform": runtime.GOOS},
+		},
+		user:    make(map[string]interface{}),
+		created: time.Now().UTC(),
+	}
+	if u, err := user.Current(); err == nil {
+		name := u.Username
+		// username of windows is in the form of DOMAIN\user
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			e.user["domain"] = name[:i]
+			name = name[i+1:]
+		}
+		e.user["name"] = name
+		// id is the SID on windows, e.g. S-1-5-21-...-1001, and the numeric uid on other systems,
+		// as winlogbeat and auditbeat record user.id
+		e.user["id"] = u.Uid
+		if u.Name != "" {
+			e.user["full_name"] = u.Name
+		}
+	}
+	return e
+}
+
+func (e *ecsWriter) write(w io.Writer, data *Data, source Source) error {
+	records, typ, _ := sourceRecords(source)
+	fields := recordFields(typ)
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	encoder.SetEscapeHTML(false)
+	for i := 0; i < records.Len(); i++ {
+		bw.WriteString(ecsAction + "\n")
+		if err := encoder.Encode(e.document(data, source.Name(), record(records, i), fields)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (e *ecsWriter) document(data *Data, artifact string, rec reflect.Value, fields []reflect.StructField) map[string]interface{} {
+	category, typ := "web", "info"
+	if c, ok := ecsCategories[artifact]; ok {
+		category, typ = c[0], c[1]
+	}
+	timestamp := recordTime(rec, fields)
+	if timestamp.IsZero() {
+		timestamp = e.created
+	}
+	original := map[string]interface{}{
+		"browser":  data.browser,
+		"profile":  data.profile,
+		"artifact": artifact,
+	}
+	for _, f := range fields {
+		v := rec.FieldByIndex(f.Index)
+		if v.IsZero() {
+			continue
+		}
+		original[fieldName(f)] = v.Interface()
+	}
+	if p := recordProvenance(rec); p != nil {
+		original["provenance"] = p
+	}
+	doc := map[string]interface{}{
+		"@timestamp": timestamp.UTC(),
+		"ecs":        map[string]interface{}{"version": ecsVersion},
+		"event": map[string]interface{}{
+			"kind":     "event",
+			"category": []string{category},
+			"type":     []string{typ},
+			"action":   artifact,
+			"dataset":  ecsNamespace + "." + typeutil.SnakeCase(artifact),
+			"module":   ecsNamespace,
+			"provider": data.browser,
+			"created":  e.created,
+		},
+		"host":       e.host,
+		"user":       e.user,
+		"labels":     map[string]interface{}{"browser": data.browser, "profile": data.profile},
+		ecsNamespace: original,
+	}
+
+	switch artifact {
+	case "cookie", "extensionCookie":
+		ecsSet(doc, "url.domain", strings.TrimPrefix(recordString(rec, "Host"), "."))
+		ecsSet(doc, "url.path", recordString(rec, "Path"))
+	case "origin":
+		ecsSet(doc, "url.scheme", strings.TrimSuffix(recordString(rec, "Origin"), "://"))
+		ecsSet(doc, "url.domain", recordString(rec, "Host"))
+	case "extension":
+		ecsSet(doc, "package.name", recordString(rec, "Name"))
+		ecsSet(doc, "package.description", recordString(rec, "Description"))
+		ecsSet(doc, "package.version", recordString(rec, "Version"))
+		ecsSet(doc, "package.reference", recordString(rec, "HomepageURL"))
+		ecsSet(doc, "package.type", "extension")
+	default:
+		for _, name := range []string{"URL", "LoginURL"} {
+			if s := recordString(rec, name); s != "" {
+				ecsSetURL(doc, s)
+				break
+			}
+		}
+	}
+	switch artifact {
+	case "password":
+		ecsSet(doc, "url.username", recordString(rec, "UserName"))
+	case "download":
+		ecsSetFile(doc, recordString(rec, "TargetPath"))
+		ecsSet(doc, "file.mime_type", recordString(rec, "MimeType"))
+		if v := rec.FieldByName("TotalBytes"); v.IsValid() && v.Int() > 0 {
+			ecsSet(doc, "file.size", v.Int())
+		}
+		start, end := recordTimeOf(rec, "StartTime"), recordTimeOf(rec, "EndTime")
+		if !start.IsZero() {
+			ecsSet(doc, "event.start", start.UTC())
+		}
+		if !end.IsZero() && end.After(start) {
+			ecsSet(doc, "event.end", end.UTC())
+			ecsSet(doc, "event.duration", end.Sub(start).Nanoseconds())
+		}
+	case "visit":
+		if v := rec.FieldByName("VisitDurationUs"); v.IsValid() && v.Int() > 0 {
+			// event.duration of ECS is in nanoseconds
+			ecsSet(doc, "event.duration", (time.Duration(v.Int()) * time.Microsecond).Nanoseconds())
+		}
+	}
+	return doc
+}
+
+// ecsSetURL sets url fields of document from the original url
+func ecsSetURL(doc map[string]interface{}, raw string) {
+	ecsSet(doc, "url.original", raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return
+	}
+	// credentials in url are not kept in the full url
+	u.User = nil
+	ecsSet(doc, "url.full", u.String())
+	ecsSet(doc, "url.scheme", u.Scheme)
+	ecsSet(doc, "url.domain", u.Hostname())
+	ecsSet(doc, "url.path", u.Path)
+	ecsSet(doc, "url.query", u.RawQuery)
+	ecsSet(doc, "url.fragment", u.Fragment)
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		ecsSet(doc, "url.port", port)
+	}
+}
+
+// ecsSetFile sets file fields of document from the file path, which may be a windows path
+func ecsSetFile(doc map[string]interface{}, path string) {
+	if path == "" {
+		return
+	}
+	ecsSet(doc, "file.path", path)
+	name := path[strings.LastIndexAny(path, `/\`)+1:]
+	ecsSet(doc, "file.name", name)
+	if i := strings.LastIndex(name, "."); i > 0 && i < len(name)-1 {
+		ecsSet(doc, "file.extension", strings.ToLower(name[i+1:]))
+	}
+}
+
+// ecsSet sets the field of document at the dotted path, empty string is not set
+func ecsSet(doc map[string]interface{}, path string, value interface{}) {
+	if s, ok := value.(string); ok && s == "" {
+		return
+	}
+	keys := strings.Split(path, ".")
+	m := doc
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[key] = child
+		}
+		m = child
+	}
+	m[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,208 @@
+package browingdata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/bookmark"
+	"hack-browser-data/internal/browingdata/cookie"
+	"hack-browser-data/internal/browingdata/download"
+	"hack-browser-data/internal/browingdata/extension"
+	"hack-browser-data/internal/browingdata/history"
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/item"
+)
+
+func TestECSWriter(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	source := &password.ChromiumPassword{}
+	testRecords(t, source, `[{"login_url":"https://github.com:8443/login?next=%2F","user_name":"user","password":"secret","create_date":"2022-01-02T03:04:05Z"}]`)
+	data.sources[item.ChromiumPassword] = source
+	var b bytes.Buffer
+	if err := newECSWriter().write(&b, data, source); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&b)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || lines[0] != ecsAction {
+		t.Fatalf("got %q, want an action line and a document", lines)
+	}
+	var doc struct {
+		Timestamp string `json:"@timestamp"`
+		Event     struct {
+			Category []string
+			Dataset  string
+		}
+		URL struct {
+			Domain   string
+			Port     int
+			Path     string
+			Username string
+		}
+		Host struct {
+			Hostname string
+		}
+		Original map[string]interface{} `json:"hack_browser_data"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Timestamp != "2022-01-02T03:04:05Z" {
+		t.Errorf("got @timestamp %s, want the create date", doc.Timestamp)
+	}
+	if len(doc.Event.Category) != 1 || doc.Event.Category[0] != "authentication" || doc.Event.Dataset != "hack_browser_data.password" {
+		t.Errorf("got event %+v, want authentication of password dataset", doc.Event)
+	}
+	if doc.URL.Domain != "github.com" || doc.URL.Port != 8443 || doc.URL.Path != "/login" || doc.URL.Username != "user" {
+		t.Errorf("got url %+v", doc.URL)
+	}
+	if doc.Original["profile"] != "Default" || doc.Original["password"] != "secret" {
+		t.Errorf("got original record %v", doc.Original)
+	}
+}
+
+func TestECSSetFile(t *testing.T) {
+	t.Parallel()
+
+	doc := make(map[string]interface{})
+	ecsSetFile(doc, `C:\Users\user\Downloads\Setup.EXE`)
+	file := doc["file"].(map[string]interface{})
+	if file["name"] != "Setup.EXE" || file["extension"] != "exe" {
+		t.Errorf("got file %v, want name Setup.EXE and extension exe", file)
+	}
+}
+
+func TestECSDocument(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		item    item.Item
+		source  Source
+		records string
+		want    map[string]interface{}
+	}{
+		{
+			item.ChromiumHistory, &history.ChromiumHistory{},
+			`[{"title":"GitHub","url":"https://github.com/search?q=go#top","visit_count":3,"last_visit_time":"2022-01-02T03:04:05Z"}]`,
+			map[string]interface{}{
+				"@timestamp":     "2022-01-02T03:04:05Z",
+				"event.category": []interface{}{"web"},
+				"event.type":     []interface{}{"access"},
+				"event.action":   "history",
+				"event.dataset":  "hack_browser_data.history",
+				"url.full":       "https://github.com/search?q=go#top",
+				"url.domain":     "github.com",
+				"url.path":       "/search",
+				"url.query":      "q=go",
+				"url.fragment":   "top",
+			},
+		},
+		{
+			item.ChromiumVisit, &history.ChromiumVisit{},
+			`[{"id":42,"url":"https://github.com","visit_time":"2022-01-02T03:04:05Z","visit_duration_us":1500000}]`,
+			map[string]interface{}{
+				"event.action":   "visit",
+				"event.duration": float64(1500 * time.Millisecond),
+				"url.domain":     "github.com",
+			},
+		},
+		{
+			item.ChromiumDownload, &download.ChromiumDownload{},
+			`[{"target_path":"/home/user/Downloads/go.tar.gz","url":"https://go.dev/dl/go.tar.gz","total_bytes":1024,"start_time":"2022-01-02T03:04:05Z","end_time":"2022-01-02T03:04:07Z","mime_type":"application/gzip"}]`,
+			map[string]interface{}{
+				"event.category": []interface{}{"file"},
+				"event.type":     []interface{}{"creation"},
+				"event.start":    "2022-01-02T03:04:05Z",
+				"event.end":      "2022-01-02T03:04:07Z",
+				"event.duration": float64(2 * time.Second),
+				"file.path":      "/home/user/Downloads/go.tar.gz",
+				"file.name":      "go.tar.gz",
+				"file.extension": "gz",
+				"file.mime_type": "application/gzip",
+				"file.size":      float64(1024),
+				"url.domain":     "go.dev",
+			},
+		},
+		{
+			item.ChromiumCookie, &cookie.ChromiumCookie{},
+			`[{"host":".github.com","path":"/","key_name":"user_session","create_date":"2022-01-02T03:04:05Z"}]`,
+			map[string]interface{}{
+				"event.category": []interface{}{"web"},
+				"event.type":     []interface{}{"info"},
+				"url.domain":     "github.com",
+				"url.path":       "/",
+			},
+		},
+		{
+			item.ChromiumExtension, &extension.ChromiumExtension{},
+			`[{"name":"uBlock Origin","description":"An efficient blocker","version":"1.46.0","homepage_url":"https://github.com/gorhill/uBlock"}]`,
+			map[string]interface{}{
+				"event.category":      []interface{}{"package"},
+				"event.type":          []interface{}{"info"},
+				"package.name":        "uBlock Origin",
+				"package.description": "An efficient blocker",
+				"package.version":     "1.46.0",
+				"package.reference":   "https://github.com/gorhill/uBlock",
+				"package.type":        "extension",
+			},
+		},
+		{
+			item.ChromiumBookmarkChange, &bookmark.ChromiumBookmarkChange{},
+			`[{"change":"removed","type":"url","name":"GitHub","url":"https://github.com","change_time":"2022-01-02T03:04:05Z"}]`,
+			map[string]interface{}{
+				"event.category": []interface{}{"configuration"},
+				"event.type":     []interface{}{"change"},
+				"url.domain":     "github.com",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		testRecords(t, tc.source, tc.records)
+		data := New("Chrome", "Default", nil)
+		data.sources[tc.item] = tc.source
+		var b bytes.Buffer
+		if err := newECSWriter().write(&b, data, tc.source); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got %d lines of %s, want an action line and a document", len(lines), tc.source.Name())
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[1]), &doc); err != nil {
+			t.Fatal(err)
+		}
+		for path, want := range tc.want {
+			if got := ecsField(doc, path); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s %v of %s, want %v", path, got, tc.source.Name(), want)
+			}
+		}
+	}
+}
+
+// ecsField returns the field of document at the dotted path
+func ecsField(doc map[string]interface{}, path string) interface{} {
+	if v, ok := doc[path]; ok {
+		return v
+	}
+	var v interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
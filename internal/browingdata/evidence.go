@@ -0,0 +1,157 @@
+package browingdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/fileutil"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+const evidenceFile = "manifest.json"
+
+// SetEvidence sets the evidence of files of items copied from browser profile
+func (d *Data) SetEvidence(evidence map[item.Item][]*fileutil.Evidence) {
+	d.evidence = evidence
+}
+
+// VerifyEvidence returns an error if any source is changed during copy or its copy doesn't match
+func (d *Data) VerifyEvidence() error {
+	var changed []string
+	for _, i := range sortedItems(d.evidence) {
+		for _, e := range d.evidence[i] {
+			if !e.Verified {
+				changed = append(changed, e.Source.Path)
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", fileutil.ErrSourceChanged, strings.Join(changed, ", "))
+}
+
+// OutputEvidence writes only the evidence manifest of browsers without their data, it's used when
+// browsers are refused for unverified evidence, so no results are written but what was read is recorded.
+func OutputEvidence(dir string, browsers []*Data, outputters []Outputter) error {
+	for _, o := range outputters {
+		e, ok := o.(*evidenceOutputter)
+		if !ok {
+			continue
+		}
+		for _, d := range browsers {
+			if err := e.Write(dir, "", d); err != nil {
+				return err
+			}
+		}
+		return e.Close(dir)
+	}
+	return nil
+}
+
+func sortedItems(evidence map[item.Item][]*fileutil.Evidence) []item.Item {
+	items := typeutil.Keys(evidence)
+	sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+	return items
+}
+
+// evidenceManifest is the chain of custody of browser profiles, it records hashes, sizes and MAC times
+// of every source file and its copy. It's written deterministically to be signed, e.g. by gpg --detach-sign.
+type evidenceManifest struct {
+	ToolVersion string           `json:"tool_version"`
+	Hostname    string           `json:"hostname"`
+	User        string           `json:"user"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Strict      bool             `json:"strict"`
+	Verified    bool             `json:"verified"`
+	Entries     []*evidenceEntry `json:"entries"`
+}
+
+type evidenceEntry struct {
+	Browser string `json:"browser"`
+	Profile string `json:"profile"`
+	Item    string `json:"item"`
+	*fileutil.Evidence
+}
+
+// evidenceOutputter collects evidence of browser profiles, and writes them as manifest.json when it's closed
+type evidenceOutputter struct {
+	host     envelopeHost
+	options  OutputOptions
+	entries  []*evidenceEntry
+	manifest *manifestOutputter
+}
+
+func newEvidenceOutputter(options OutputOptions) *evidenceOutputter {
+	return &evidenceOutputter{host: newEnvelopeHost(), options: options}
+}
+
+func (o *evidenceOutputter) Write(_, _ string, data *Data) error {
+	for _, i := range sortedItems(data.evidence) {
+		for _, e := range data.evidence[i] {
+			o.entries = append(o.entries, &evidenceEntry{
+				Browser:  data.browser,
+				Profile:  data.profile,
+				Item:     typeutil.SnakeCase(i.String()),
+				Evidence: e,
+			})
+		}
+	}
+	return nil
+}
+
+func (o *evidenceOutputter) Close(dir string) error {
+	if err := o.writeManifest(dir); err != nil {
+		return err
+	}
+	log.Noticef("output to file %s success", outputPath(dir, evidenceFile))
+	if o.manifest != nil {
+		o.manifest.addFile(dir, evidenceFile, "json", nil, nil)
+	}
+	return nil
+}
+
+func (o *evidenceOutputter) writeManifest(dir string) error {
+	if dir == StdoutDir {
+		return errors.New("evidence manifest can't be written to stdout")
+	}
+	sort.SliceStable(o.entries, func(i, j int) bool {
+		a, b := o.entries[i], o.entries[j]
+		if a.Browser != b.Browser {
+			return a.Browser < b.Browser
+		}
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		return a.Source.Path < b.Source.Path
+	})
+	m := evidenceManifest{
+		ToolVersion: o.options.ToolVersion,
+		Hostname:    o.host.hostname,
+		User:        o.host.user,
+		GeneratedAt: time.Now().UTC(),
+		Strict:      o.options.Strict,
+		Verified:    true,
+		Entries:     o.entries,
+	}
+	for _, e := range o.entries {
+		m.Verified = m.Verified && e.Verified
+	}
+	if m.Entries == nil {
+		m.Entries = []*evidenceEntry{}
+	}
+	f, err := createFile(dir, evidenceFile)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,95 @@
+package browingdata
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/utils/fileutil"
+)
+
+func TestEvidence(t *testing.T) {
+	t.Parallel()
+
+	verified := &fileutil.Evidence{
+		Source:   &fileutil.EvidenceFile{Path: filepath.Join("Default", "History"), Size: 4},
+		Copy:     &fileutil.EvidenceFile{Path: "history", Size: 4},
+		Verified: true,
+	}
+	changed := &fileutil.Evidence{
+		Source: &fileutil.EvidenceFile{Path: filepath.Join("Default", "Login Data"), Size: 4},
+		Copy:   &fileutil.EvidenceFile{Path: "password", Size: 2},
+	}
+	data := New("Chrome", "Default", nil)
+	data.SetEvidence(map[item.Item][]*fileutil.Evidence{item.ChromiumHistory: {verified}})
+	if err := data.VerifyEvidence(); err != nil {
+		t.Errorf("VerifyEvidence() error %s", err)
+	}
+	data.evidence[item.ChromiumPassword] = []*fileutil.Evidence{changed}
+	if err := data.VerifyEvidence(); !errors.Is(err, fileutil.ErrSourceChanged) {
+		t.Errorf("VerifyEvidence() error %v, want %s", err, fileutil.ErrSourceChanged)
+	}
+
+	dir := t.TempDir()
+	o := newEvidenceOutputter(OutputOptions{ToolVersion: "1.0.0", Strict: true})
+	if err := o.Write(dir, "chrome_default", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.writeManifest(dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, evidenceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got evidenceManifest
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ToolVersion != "1.0.0" || !got.Strict || got.Verified {
+		t.Errorf("got tool version %s, strict %t and verified %t", got.ToolVersion, got.Strict, got.Verified)
+	}
+	if len(got.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got.Entries))
+	}
+	// entries are sorted by source path
+	if e := got.Entries[0]; e.Item != "history" || e.Browser != "Chrome" || e.Profile != "Default" || !e.Verified {
+		t.Errorf("got entry %s of %s %s verified %t", e.Item, e.Browser, e.Profile, e.Verified)
+	}
+	if e := got.Entries[1]; e.Item != "password" || e.Verified || e.Copy.Size != 2 {
+		t.Errorf("got entry %s verified %t copy size %d", e.Item, e.Verified, e.Copy.Size)
+	}
+	if err := o.writeManifest(StdoutDir); err == nil {
+		t.Error("writeManifest() to stdout returned no error")
+	}
+}
+
+func TestOutputEvidence(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	data.sources[item.ChromiumPassword] = &testLogins{{URL: "https://github.com/login", UserName: "user"}}
+	data.SetEvidence(map[item.Item][]*fileutil.Evidence{item.ChromiumPassword: {{
+		Source: &fileutil.EvidenceFile{Path: filepath.Join("Default", "Login Data"), Size: 4},
+		Copy:   &fileutil.EvidenceFile{Path: "password", Size: 2},
+	}}})
+	outputters, err := NewOutputters([]string{"csv", "sqlite"}, OutputOptions{Manifest: true, Evidence: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := OutputEvidence(dir, []*Data{data}, outputters); err != nil {
+		t.Fatal(err)
+	}
+	// refused browsers have only the evidence manifest written
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != evidenceFile {
+		t.Errorf("got %d files in results dir, want only %s", len(entries), evidenceFile)
+	}
+}
@@ -2,6 +2,7 @@ package extension
 
 import (
 	"os"
+	"path/filepath"
 
 	"hack-browser-data/internal/item"
 	"hack-browser-data/internal/log"
@@ -13,10 +14,12 @@ import (
 type ChromiumExtension []*extension
 
 type extension struct {
-	Name        string
-	Description string
-	Version     string
-	HomepageURL string
+	Name        string `json:"name" csv:"name"`
+	Description string `json:"description" csv:"description"`
+	Version     string `json:"version" csv:"version"`
+	HomepageURL string `json:"homepage_url" csv:"homepage_url"`
+	// Provenance is the manifest file or the JSON pointer of extension
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
@@ -36,12 +39,17 @@ func (c *ChromiumExtension) Parse(masterKey []byte) error {
 			continue
 		}
 		b := gjson.Parse(file)
-		*c = append(*c, &extension{
+		e := &extension{
 			Name:        b.Get("name").String(),
 			Description: b.Get("description").String(),
 			Version:     b.Get("version").String(),
 			HomepageURL: b.Get("homepage_url").String(),
-		})
+			Provenance:  &item.Provenance{},
+		}
+		if rel, err := filepath.Rel(item.TempChromiumExtension, f); err == nil {
+			e.Provenance.File = rel
+		}
+		*c = append(*c, e)
 	}
 	return nil
 }
@@ -63,12 +71,13 @@ func (f *FirefoxExtension) Parse(masterKey []byte) error {
 	}
 	defer os.Remove(item.TempFirefoxExtension)
 	j := gjson.Parse(s)
-	for _, v := range j.Get("addons").Array() {
+	for i, v := range j.Get("addons").Array() {
 		*f = append(*f, &extension{
 			Name:        v.Get("defaultLocale.name").String(),
 			Description: v.Get("defaultLocale.description").String(),
 			Version:     v.Get("version").String(),
 			HomepageURL: v.Get("defaultLocale.homepageURL").String(),
+			Provenance:  item.PointerProvenance(item.JSONPointer("addons", i)),
 		})
 	}
 	return nil
@@ -17,14 +17,16 @@ import (
 type ChromiumHistory []history
 
 type history struct {
-	Title         string
-	URL           string
-	VisitCount    int
-	LastVisitTime time.Time
+	Title         string    `json:"title" csv:"title"`
+	URL           string    `json:"url" csv:"url"`
+	VisitCount    int       `json:"visit_count" csv:"visit_count"`
+	LastVisitTime time.Time `json:"last_visit_time" csv:"last_visit_time"`
+	// Provenance is the row of url
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
-	queryChromiumHistory = `SELECT url, title, visit_count, last_visit_time FROM urls`
+	queryChromiumHistory = `SELECT rowid, url, title, visit_count, last_visit_time FROM urls`
 )
 
 func (c *ChromiumHistory) Parse(masterKey []byte) error {
@@ -41,11 +43,11 @@ func (c *ChromiumHistory) Parse(masterKey []byte) error {
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			url, title    string
-			visitCount    int
-			lastVisitTime int64
+			url, title        string
+			visitCount        int
+			id, lastVisitTime int64
 		)
-		if err := rows.Scan(&url, &title, &visitCount, &lastVisitTime); err != nil {
+		if err := rows.Scan(&id, &url, &title, &visitCount, &lastVisitTime); err != nil {
 			log.Warn(err)
 		}
 		data := history{
@@ -53,6 +55,7 @@ func (c *ChromiumHistory) Parse(masterKey []byte) error {
 			Title:         title,
 			VisitCount:    visitCount,
 			LastVisitTime: typeutil.TimeEpoch(lastVisitTime),
+			Provenance:    item.RowProvenance("urls", id),
 		}
 		*c = append(*c, data)
 	}
@@ -113,6 +116,7 @@ func (f *FirefoxHistory) Parse(masterKey []byte) error {
 			URL:           url,
 			VisitCount:    visitCount,
 			LastVisitTime: typeutil.TimeStamp(visitDate / 1000000),
+			Provenance:    item.RowProvenance("moz_places", id),
 		})
 	}
 	sort.Slice(*f, func(i, j int) bool {
@@ -128,3 +132,125 @@ func (f *FirefoxHistory) Name() string {
 func (f *FirefoxHistory) Length() int {
 	return len(*f)
 }
+
+// FirefoxInputHistory is what the user typed in the address bar to reach a page
+type FirefoxInputHistory []inputHistory
+
+type inputHistory struct {
+	URL      string  `json:"url" csv:"url"`
+	Title    string  `json:"title" csv:"title"`
+	Input    string  `json:"input" csv:"input"`
+	UseCount float64 `json:"use_count" csv:"use_count"`
+	// Provenance is the row of input
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryFirefoxInputHistory = `SELECT i.rowid, p.url, COALESCE(p.title, ''), i.input, i.use_count FROM moz_inputhistory i INNER JOIN moz_places p ON i.place_id = p.id`
+)
+
+func (f *FirefoxInputHistory) Parse(masterKey []byte) error {
+	placesDB, err := sql.Open("sqlite3", item.TempFirefoxInputHistory)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempFirefoxInputHistory)
+	defer placesDB.Close()
+	_, err = placesDB.Exec(closeJournalMode)
+	if err != nil {
+		return err
+	}
+	rows, err := placesDB.Query(queryFirefoxInputHistory)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			rowid             int64
+			url, title, input string
+			useCount          float64
+		)
+		if err = rows.Scan(&rowid, &url, &title, &input, &useCount); err != nil {
+			log.Warn(err)
+		}
+		*f = append(*f, inputHistory{
+			URL:        url,
+			Title:      title,
+			Input:      input,
+			UseCount:   useCount,
+			Provenance: item.RowProvenance("moz_inputhistory", rowid),
+		})
+	}
+	sort.Slice(*f, func(i, j int) bool {
+		return (*f)[i].UseCount > (*f)[j].UseCount
+	})
+	return nil
+}
+
+func (f *FirefoxInputHistory) Name() string {
+	return "inputHistory"
+}
+
+func (f *FirefoxInputHistory) Length() int {
+	return len(*f)
+}
+
+type FirefoxOrigin []origin
+
+type origin struct {
+	Origin   string `json:"origin" csv:"origin"`
+	Host     string `json:"host" csv:"host"`
+	Frecency int64  `json:"frecency" csv:"frecency"`
+	// Provenance is the row of origin
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryFirefoxOrigin = `SELECT rowid, prefix, host, frecency FROM moz_origins`
+)
+
+func (f *FirefoxOrigin) Parse(masterKey []byte) error {
+	placesDB, err := sql.Open("sqlite3", item.TempFirefoxOrigin)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempFirefoxOrigin)
+	defer placesDB.Close()
+	_, err = placesDB.Exec(closeJournalMode)
+	if err != nil {
+		return err
+	}
+	rows, err := placesDB.Query(queryFirefoxOrigin)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			prefix, host string
+			id, frecency int64
+		)
+		if err = rows.Scan(&id, &prefix, &host, &frecency); err != nil {
+			log.Warn(err)
+		}
+		*f = append(*f, origin{
+			Origin:     prefix + host,
+			Host:       host,
+			Frecency:   frecency,
+			Provenance: item.RowProvenance("moz_origins", id),
+		})
+	}
+	sort.Slice(*f, func(i, j int) bool {
+		return (*f)[i].Frecency > (*f)[j].Frecency
+	})
+	return nil
+}
+
+func (f *FirefoxOrigin) Name() string {
+	return "origin"
+}
+
+func (f *FirefoxOrigin) Length() int {
+	return len(*f)
+}
@@ -0,0 +1,286 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/typeutil"
+
+	// import sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type ChromiumVisit []visit
+
+type visit struct {
+	ID         int64     `json:"id" csv:"id"`
+	URL        string    `json:"url" csv:"url"`
+	Title      string    `json:"title" csv:"title"`
+	VisitTime  time.Time `json:"visit_time" csv:"visit_time"`
+	Transition string    `json:"transition" csv:"transition"`
+	Qualifiers string    `json:"qualifiers" csv:"qualifiers"`
+	FromVisit  int64     `json:"from_visit" csv:"from_visit"`
+	FromURL    string    `json:"from_url" csv:"from_url"`
+	// VisitDurationUs is how long the page is visited in microseconds in every format, firefox doesn't record it
+	VisitDurationUs int64  `json:"visit_duration_us" csv:"visit_duration_us"`
+	TypedCount      int    `json:"typed_count" csv:"typed_count"`
+	VisitSource     string `json:"visit_source" csv:"visit_source"`
+	// Provenance is the row of visit
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryChromiumVisit = `SELECT visits.id, urls.url, COALESCE(urls.title, ''), visits.visit_time, visits.transition, visits.from_visit,
+		COALESCE(from_urls.url, ''), visits.visit_duration, urls.typed_count, %s
+		FROM visits
+		INNER JOIN urls ON visits.url = urls.id
+		LEFT JOIN visits AS from_visits ON visits.from_visit = from_visits.id
+		LEFT JOIN urls AS from_urls ON from_visits.url = from_urls.id %s`
+	queryTableExists = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+)
+
+func (c *ChromiumVisit) Parse(masterKey []byte) error {
+	historyDB, err := sql.Open("sqlite3", item.TempChromiumVisit)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempChromiumVisit)
+	defer historyDB.Close()
+
+	// visits without a row in visit_source are browsed on this device
+	source, join := "1", ""
+	var exists int
+	if err := historyDB.QueryRow(queryTableExists, "visit_source").Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		source, join = "COALESCE(visit_source.source, 1)", "LEFT JOIN visit_source ON visits.id = visit_source.id"
+	}
+	rows, err := historyDB.Query(fmt.Sprintf(queryChromiumVisit, source, join))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			url, title, fromURL                              string
+			id, visitTime, transition, fromVisit, durationUs int64
+			typedCount, visitSource                          int
+		)
+		if err := rows.Scan(&id, &url, &title, &visitTime, &transition, &fromVisit, &fromURL, &durationUs, &typedCount, &visitSource); err != nil {
+			log.Warn(err)
+		}
+		*c = append(*c, visit{
+			ID:              id,
+			URL:             url,
+			Title:           title,
+			VisitTime:       typeutil.TimeEpoch(visitTime),
+			Transition:      chromiumTransition(transition),
+			Qualifiers:      chromiumQualifiers(transition),
+			FromVisit:       fromVisit,
+			FromURL:         fromURL,
+			VisitDurationUs: durationUs,
+			TypedCount:      typedCount,
+			VisitSource:     chromiumVisitSource(visitSource),
+			Provenance:      item.RowProvenance("visits", id),
+		})
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].VisitTime.After((*c)[j].VisitTime)
+	})
+	return nil
+}
+
+func (c *ChromiumVisit) Name() string {
+	return "visit"
+}
+
+func (c *ChromiumVisit) Length() int {
+	return len(*c)
+}
+
+// chromiumTransition returns the core type of page transition, which is stored in the low byte
+// see https://source.chromium.org/chromium/chromium/src/+/main:ui/base/page_transition_types.h
+func chromiumTransition(t int64) string {
+	transitions := []string{
+		"link",
+		"typed",
+		"auto_bookmark",
+		"auto_subframe",
+		"manual_subframe",
+		"generated",
+		"auto_toplevel",
+		"form_submit",
+		"reload",
+		"keyword",
+		"keyword_generated",
+	}
+	core := t & 0xFF
+	if core < int64(len(transitions)) {
+		return transitions[core]
+	}
+	return "unknown"
+}
+
+// chromiumQualifiers returns the qualifiers of page transition joined with |, e.g. chain_start|server_redirect
+func chromiumQualifiers(t int64) string {
+	qualifiers := []struct {
+		mask int64
+		name string
+	}{
+		{0x00800000, "blocked"},
+		{0x01000000, "forward_back"},
+		{0x02000000, "from_address_bar"},
+		{0x04000000, "home_page"},
+		{0x08000000, "from_api"},
+		{0x10000000, "chain_start"},
+		{0x20000000, "chain_end"},
+		{0x40000000, "client_redirect"},
+		{0x80000000, "server_redirect"},
+	}
+	var s []string
+	for _, q := range qualifiers {
+		if t&q.mask != 0 {
+			s = append(s, q.name)
+		}
+	}
+	return strings.Join(s, "|")
+}
+
+// chromiumVisitSource tells whether the visit is browsed on this device, arrived via sync or imported
+func chromiumVisitSource(s int) string {
+	switch s {
+	case 0:
+		return "synced"
+	case 1:
+		return "local"
+	case 2:
+		return "extension"
+	case 3, 4, 5:
+		return "imported"
+	default:
+		return "unknown"
+	}
+}
+
+type FirefoxVisit []visit
+
+const (
+	queryFirefoxVisit = `SELECT v.id, p.url, COALESCE(p.title, ''), v.visit_date, v.visit_type, v.from_visit, COALESCE(fp.url, ''),
+		(SELECT COUNT(*) FROM moz_historyvisits t WHERE t.place_id = p.id AND t.visit_type = 2), %s
+		FROM moz_historyvisits v
+		INNER JOIN moz_places p ON v.place_id = p.id
+		LEFT JOIN moz_historyvisits fv ON v.from_visit = fv.id
+		LEFT JOIN moz_places fp ON fv.place_id = fp.id`
+	queryTableColumn = `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+)
+
+func (f *FirefoxVisit) Parse(masterKey []byte) error {
+	placesDB, err := sql.Open("sqlite3", item.TempFirefoxVisit)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempFirefoxVisit)
+	defer placesDB.Close()
+	_, err = placesDB.Exec(closeJournalMode)
+	if err != nil {
+		return err
+	}
+
+	// the source of visit is added in Firefox 114, older visits are treated as organic
+	source := "0"
+	var exists int
+	if err := placesDB.QueryRow(queryTableColumn, "moz_historyvisits", "source").Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		source = "v.source"
+	}
+	rows, err := placesDB.Query(fmt.Sprintf(queryFirefoxVisit, source))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			url, title, fromURL                string
+			id, visitDate, fromVisit           int64
+			visitType, typedCount, visitSource int
+		)
+		if err := rows.Scan(&id, &url, &title, &visitDate, &visitType, &fromVisit, &fromURL, &typedCount, &visitSource); err != nil {
+			log.Warn(err)
+		}
+		transition, qualifiers := firefoxTransition(visitType)
+		*f = append(*f, visit{
+			ID:          id,
+			URL:         url,
+			Title:       title,
+			VisitTime:   typeutil.TimeStamp(visitDate / 1000000),
+			Transition:  transition,
+			Qualifiers:  qualifiers,
+			FromVisit:   fromVisit,
+			FromURL:     fromURL,
+			TypedCount:  typedCount,
+			VisitSource: firefoxVisitSource(visitSource),
+			Provenance:  item.RowProvenance("moz_historyvisits", id),
+		})
+	}
+	sort.Slice(*f, func(i, j int) bool {
+		return (*f)[i].VisitTime.After((*f)[j].VisitTime)
+	})
+	return nil
+}
+
+func (f *FirefoxVisit) Name() string {
+	return "visit"
+}
+
+func (f *FirefoxVisit) Length() int {
+	return len(*f)
+}
+
+// firefoxTransition maps the visit type of firefox to the transition and qualifiers of chromium,
+// so visits of both browsers share the same values. Chromium doesn't tell permanent redirects from
+// temporary ones, and downloads are navigated by links.
+// see https://searchfox.org/mozilla-central/source/toolkit/components/places/nsINavHistoryService.idl
+func firefoxTransition(t int) (transition, qualifiers string) {
+	switch t {
+	case 1:
+		return "link", ""
+	case 2:
+		return "typed", ""
+	case 3:
+		return "auto_bookmark", ""
+	case 4:
+		return "auto_subframe", ""
+	case 5, 6:
+		return "link", "server_redirect"
+	case 7:
+		return "link", ""
+	case 8:
+		return "manual_subframe", ""
+	case 9:
+		return "reload", ""
+	default:
+		return "unknown", ""
+	}
+}
+
+// firefoxVisitSource tells whether the visit is browsed on this device or arrived via sync,
+// organic, sponsored, bookmarked and searched visits are all browsed on this device.
+func firefoxVisitSource(s int) string {
+	switch s {
+	case 0, 1, 2, 3:
+		return "local"
+	case 4:
+		return "synced"
+	default:
+		return "unknown"
+	}
+}
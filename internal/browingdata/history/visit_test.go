@@ -0,0 +1,187 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/item"
+)
+
+func TestChromiumTransition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		transition     int64
+		wantTransition string
+		wantQualifiers string
+	}{
+		{0, "link", ""},
+		{1, "typed", ""},
+		{0x30000001, "typed", "chain_start|chain_end"},
+		{0x02000001, "typed", "from_address_bar"},
+		{0x80000000, "link", "server_redirect"},
+		{0x4100000A, "keyword_generated", "forward_back|client_redirect"},
+		{0x08800008, "reload", "blocked|from_api"},
+		{0x04000006, "auto_toplevel", "home_page"},
+		{0xFF, "unknown", ""},
+	}
+	for _, tc := range testCases {
+		if got := chromiumTransition(tc.transition); got != tc.wantTransition {
+			t.Errorf("chromiumTransition(%#x) = %s, want %s", tc.transition, got, tc.wantTransition)
+		}
+		if got := chromiumQualifiers(tc.transition); got != tc.wantQualifiers {
+			t.Errorf("chromiumQualifiers(%#x) = %s, want %s", tc.transition, got, tc.wantQualifiers)
+		}
+	}
+}
+
+func TestChromiumVisitSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source int
+		want   string
+	}{
+		{0, "synced"},
+		{1, "local"},
+		{2, "extension"},
+		{3, "imported"},
+		{4, "imported"},
+		{5, "imported"},
+		{6, "unknown"},
+	}
+	for _, tc := range testCases {
+		if got := chromiumVisitSource(tc.source); got != tc.want {
+			t.Errorf("chromiumVisitSource(%d) = %s, want %s", tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestFirefoxTransition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		visitType      int
+		wantTransition string
+		wantQualifiers string
+	}{
+		{1, "link", ""},
+		{2, "typed", ""},
+		{3, "auto_bookmark", ""},
+		{4, "auto_subframe", ""},
+		{5, "link", "server_redirect"},
+		{6, "link", "server_redirect"},
+		{7, "link", ""},
+		{8, "manual_subframe", ""},
+		{9, "reload", ""},
+		{0, "unknown", ""},
+	}
+	for _, tc := range testCases {
+		transition, qualifiers := firefoxTransition(tc.visitType)
+		if transition != tc.wantTransition || qualifiers != tc.wantQualifiers {
+			t.Errorf("firefoxTransition(%d) = %s, %s, want %s, %s", tc.visitType, transition, qualifiers, tc.wantTransition, tc.wantQualifiers)
+		}
+		// firefox visits share the vocabulary of chromium
+		if !isChromiumTransition(transition) {
+			t.Errorf("firefoxTransition(%d) = %s, which isn't a chromium transition", tc.visitType, transition)
+		}
+	}
+}
+
+func isChromiumTransition(transition string) bool {
+	for i := int64(0); i <= 0xFF; i++ {
+		if chromiumTransition(i) == transition {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFirefoxVisitSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source int
+		want   string
+	}{
+		{0, "local"},
+		{1, "local"},
+		{2, "local"},
+		{3, "local"},
+		{4, "synced"},
+		{5, "unknown"},
+	}
+	for _, tc := range testCases {
+		if got := firefoxVisitSource(tc.source); got != tc.want {
+			t.Errorf("firefoxVisitSource(%d) = %s, want %s", tc.source, got, tc.want)
+		}
+	}
+}
+
+// createDB creates the database of filename with stmts, which is removed by the source after parsing
+func createDB(t *testing.T, filename string, stmts []string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChromiumVisitDuration(t *testing.T) {
+	t.Parallel()
+
+	createDB(t, item.TempChromiumVisit, []string{
+		"CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT, typed_count INTEGER)",
+		"CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER, from_visit INTEGER, transition INTEGER, visit_duration INTEGER)",
+		"INSERT INTO urls VALUES (1, 'https://github.com', 'GitHub', 1)",
+		"INSERT INTO visits VALUES (42, 1, 13285566245000000, 0, 1, 1500000)",
+	})
+	visits := &ChromiumVisit{}
+	if err := visits.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*visits) != 1 {
+		t.Fatalf("got %d visits, want 1", len(*visits))
+	}
+	// duration of visit is in microseconds in every format
+	got := (*visits)[0]
+	if got.VisitDurationUs != 1500000 || got.Transition != "typed" || got.VisitSource != "local" {
+		t.Errorf("got visit %+v, want 1500000us typed local visit", got)
+	}
+	if !got.VisitTime.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("got visit time %s, want 2022-01-02T03:04:05Z", got.VisitTime)
+	}
+}
+
+func TestFirefoxVisitDuration(t *testing.T) {
+	t.Parallel()
+
+	createDB(t, item.TempFirefoxVisit, []string{
+		"CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT, title TEXT)",
+		"CREATE TABLE moz_historyvisits (id INTEGER PRIMARY KEY, from_visit INTEGER, place_id INTEGER, visit_date INTEGER, visit_type INTEGER)",
+		"INSERT INTO moz_places VALUES (1, 'https://github.com', 'GitHub')",
+		"INSERT INTO moz_historyvisits VALUES (42, 0, 1, 1641092645000000, 2)",
+	})
+	visits := &FirefoxVisit{}
+	if err := visits.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*visits) != 1 {
+		t.Fatalf("got %d visits, want 1", len(*visits))
+	}
+	// firefox doesn't record the duration of visit, so it's zero microseconds as chromium visits without it
+	got := (*visits)[0]
+	if got.VisitDurationUs != 0 || got.Transition != "typed" || got.TypedCount != 1 || got.VisitSource != "local" {
+		t.Errorf("got visit %+v, want typed local visit without duration", got)
+	}
+	if !got.VisitTime.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("got visit time %s, want 2022-01-02T03:04:05Z", got.VisitTime)
+	}
+}
@@ -0,0 +1,93 @@
+package browingdata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+func init() {
+	RegisterOutputter("jsonl", func(options OutputOptions) (Outputter, error) {
+		return &fileOutputter{ext: "jsonl", write: writeJSONL}, nil
+	})
+}
+
+// recordTag is merged into each record of jsonl format
+type recordTag struct {
+	Browser  string `json:"browser"`
+	Profile  string `json:"profile"`
+	Artifact string `json:"artifact"`
+}
+
+// writeJSONL writes each record of source as a line of json object, which is tagged with
+// the browser, profile and artifact of source. Keys of tag are dropped from records, so keys
+// of each line are unique for strict parsers, e.g. Elasticsearch.
+func writeJSONL(w io.Writer, data *Data, source Source) error {
+	tag, err := json.Marshal(recordTag{Browser: data.browser, Profile: data.profile, Artifact: source.Name()})
+	if err != nil {
+		return err
+	}
+	// the tag without the closing brace is the head of each line
+	head := tag[:len(tag)-1]
+
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	records := reflect.Indirect(reflect.ValueOf(source))
+	for i := 0; i < records.Len(); i++ {
+		buf.Reset()
+		if err := encoder.Encode(records.Index(i).Interface()); err != nil {
+			return err
+		}
+		record := bytes.TrimSpace(buf.Bytes())
+		bw.Write(head)
+		if len(record) > 0 && record[0] == '{' {
+			if err := writeUntaggedFields(bw, record); err != nil {
+				return err
+			}
+		} else {
+			bw.WriteString(`,"data":`)
+			bw.Write(record)
+		}
+		bw.WriteString("}\n")
+	}
+	return bw.Flush()
+}
+
+// tagKeys are the keys of recordTag
+var tagKeys = map[string]bool{"browser": true, "profile": true, "artifact": true}
+
+// writeUntaggedFields writes fields of json object except keys of tag, each field is preceded by a comma
+func writeUntaggedFields(w *bufio.Writer, object []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	// the opening brace
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		name, _ := key.(string)
+		if tagKeys[name] {
+			continue
+		}
+		k, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		w.WriteByte(',')
+		w.Write(k)
+		w.WriteByte(':')
+		w.Write(value)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package browingdata
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/search"
+)
+
+func TestWriteJSONL(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	source := &testLogins{
+		{URL: "https://github.com/?a=1&b=<2>", UserName: "user", CreateDate: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{URL: "https://example.com"},
+	}
+	var b strings.Builder
+	if err := writeJSONL(&b, data, source); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"browser":"Chrome","profile":"Default","artifact":"password","Browser":"","URL":"https://github.com/?a=1&b=<2>","UserName":"user","Password":"","CreateDate":"2022-01-02T03:04:05Z"}
+{"browser":"Chrome","profile":"Default","artifact":"password","Browser":"","URL":"https://example.com","UserName":"","Password":"","CreateDate":"0001-01-01T00:00:00Z"}
+`
+	if b.String() != want {
+		t.Errorf("writeJSONL() = %s, want %s", b.String(), want)
+	}
+}
+
+func TestWriteJSONLUniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	// searches carry their own browser and profile, which are the keys of tag
+	source := &search.ChromiumSearch{}
+	testRecords(t, source, `[{"browser":"Chrome","profile":"Default","engine":"google","term":"a b","url":"https://www.google.com/search?q=a+b","search_time":"2022-01-02T03:04:05Z"}]`)
+	var b strings.Builder
+	if err := writeJSONL(&b, data, source); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"browser":"Chrome","profile":"Default","artifact":"search","engine":"google","term":"a b","url":"https://www.google.com/search?q=a+b","search_time":"2022-01-02T03:04:05Z"}
+`
+	if b.String() != want {
+		t.Errorf("writeJSONL() = %s, want %s", b.String(), want)
+	}
+}
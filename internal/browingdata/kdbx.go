@@ -0,0 +1,169 @@
+package browingdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"hack-browser-data/internal/browingdata/creditcard"
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/typeutil"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	w "github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+const kdbxFile = "passwords.kdbx"
+
+func init() {
+	RegisterOutputter("kdbx", func(options OutputOptions) (Outputter, error) {
+		if options.KDBXPassword == "" && options.KDBXKeyFile == "" {
+			return nil, errors.New("password or key file is required")
+		}
+		return &mergedOutputter{write: func(dir string, browsers map[string]*Data) ([]string, error) {
+			return WriteKDBX(dir, browsers, options)
+		}}, nil
+	})
+}
+
+// cardSource is implemented by credit cards which could be written into KeePass database
+type cardSource interface {
+	Cards() []creditcard.Card
+}
+
+// WriteKDBX writes passwords, and credit cards if options.KDBXCards is set, of all browsers into
+// a single KDBX4 database protected by options.KDBXPassword and/or options.KDBXKeyFile,
+// entries are grouped by browser and profile.
+func WriteKDBX(dir string, browsers map[string]*Data, options OutputOptions) ([]string, error) {
+	db, err := newKDBX(browsers, options)
+	if err != nil || db == nil {
+		return nil, err
+	}
+	f, err := createFile(dir, kdbxFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := gokeepasslib.NewEncoder(f).Encode(db); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	log.Noticef("output to file %s success", outputPath(dir, kdbxFile))
+	return []string{kdbxFile}, nil
+}
+
+// newKDBX returns the KeePass database of browsers, or nil if there is nothing to write
+func newKDBX(browsers map[string]*Data, options OutputOptions) (*gokeepasslib.Database, error) {
+	credentials, err := kdbxCredentials(options.KDBXPassword, options.KDBXKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	root := gokeepasslib.NewGroup()
+	root.Name = "HackBrowserData"
+	browserGroups := make(map[string]int)
+	names := typeutil.Keys(browsers)
+	sort.Strings(names)
+	for _, name := range names {
+		d := browsers[name]
+		profile := gokeepasslib.NewGroup()
+		profile.Name = d.profile
+		for _, source := range d.sources {
+			if s, ok := source.(loginSource); ok {
+				for _, l := range s.Logins() {
+					profile.Entries = append(profile.Entries, loginEntry(l))
+				}
+			}
+			if s, ok := source.(cardSource); ok && options.KDBXCards {
+				for _, c := range s.Cards() {
+					profile.Entries = append(profile.Entries, cardEntry(c))
+				}
+			}
+		}
+		if len(profile.Entries) == 0 {
+			continue
+		}
+		i, ok := browserGroups[d.browser]
+		if !ok {
+			browser := gokeepasslib.NewGroup()
+			browser.Name = d.browser
+			root.Groups = append(root.Groups, browser)
+			i = len(root.Groups) - 1
+			browserGroups[d.browser] = i
+		}
+		root.Groups[i].Groups = append(root.Groups[i].Groups, profile)
+	}
+	if len(root.Groups) == 0 {
+		return nil, nil
+	}
+
+	db := gokeepasslib.NewDatabase(gokeepasslib.WithDatabaseKDBXVersion4())
+	db.Credentials = credentials
+	db.Content.Meta.DatabaseName = root.Name
+	db.Content.Root = &gokeepasslib.RootData{Groups: []gokeepasslib.Group{root}}
+	if err := db.LockProtectedEntries(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func kdbxCredentials(password, keyFile string) (*gokeepasslib.DBCredentials, error) {
+	switch {
+	case password != "" && keyFile != "":
+		return gokeepasslib.NewPasswordAndKeyCredentials(password, keyFile)
+	case keyFile != "":
+		return gokeepasslib.NewKeyCredentials(keyFile)
+	case password != "":
+		return gokeepasslib.NewPasswordCredentials(password), nil
+	default:
+		return nil, errors.New("kdbx format requires a password or a key file")
+	}
+}
+
+func loginEntry(l password.Login) gokeepasslib.Entry {
+	entry := gokeepasslib.NewEntry()
+	entry.Values = []gokeepasslib.ValueData{
+		kdbxValue("Title", l.Name()),
+		kdbxValue("UserName", l.UserName),
+		kdbxProtectedValue("Password", l.Password),
+		kdbxValue("URL", l.URL),
+	}
+	setCreationTime(&entry, l.CreateDate)
+	return entry
+}
+
+func cardEntry(c creditcard.Card) gokeepasslib.Entry {
+	title := c.NickName
+	if title == "" {
+		title = c.Name
+	}
+	entry := gokeepasslib.NewEntry()
+	entry.Tags = "creditcard"
+	entry.Values = []gokeepasslib.ValueData{
+		kdbxValue("Title", title),
+		kdbxValue("UserName", c.Name),
+		kdbxProtectedValue("Password", c.CardNumber),
+		kdbxValue("Expiration", fmt.Sprintf("%s/%s", c.ExpirationMonth, c.ExpirationYear)),
+	}
+	return entry
+}
+
+func kdbxValue(key, value string) gokeepasslib.ValueData {
+	return gokeepasslib.ValueData{Key: key, Value: gokeepasslib.V{Content: value}}
+}
+
+func kdbxProtectedValue(key, value string) gokeepasslib.ValueData {
+	return gokeepasslib.ValueData{Key: key, Value: gokeepasslib.V{Content: value, Protected: w.NewBoolWrapper(true)}}
+}
+
+func setCreationTime(entry *gokeepasslib.Entry, t time.Time) {
+	if t.IsZero() || t.Year() < 1 || t.Year() >= 10000 {
+		return
+	}
+	created := w.TimeWrapper{Time: t.UTC()}
+	entry.Times.CreationTime = &created
+}
@@ -0,0 +1,72 @@
+package browingdata
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/creditcard"
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/item"
+
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+type testLogins []password.Login
+
+func (t *testLogins) Parse(_ []byte) error     { return nil }
+func (t *testLogins) Name() string             { return "password" }
+func (t *testLogins) Length() int              { return len(*t) }
+func (t *testLogins) Logins() []password.Login { return *t }
+
+type testCards []creditcard.Card
+
+func (t *testCards) Parse(_ []byte) error     { return nil }
+func (t *testCards) Name() string             { return "creditcard" }
+func (t *testCards) Length() int              { return len(*t) }
+func (t *testCards) Cards() []creditcard.Card { return *t }
+
+func TestWriteKDBX(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	data.sources[item.ChromiumPassword] = &testLogins{
+		{URL: "https://github.com/login", UserName: "user", Password: "secret", CreateDate: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	data.sources[item.ChromiumCreditCard] = &testCards{
+		{Name: "John Doe", CardNumber: "4111111111111111", ExpirationMonth: "1", ExpirationYear: "2030"},
+	}
+	options := OutputOptions{KDBXPassword: "password", KDBXCards: true}
+	db, err := newKDBX(map[string]*Data{"chrome_default": data}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := gokeepasslib.NewEncoder(&b).Encode(db); err != nil {
+		t.Fatal(err)
+	}
+
+	db = gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials("password")
+	if err := gokeepasslib.NewDecoder(&b).Decode(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UnlockProtectedEntries(); err != nil {
+		t.Fatal(err)
+	}
+	browser := db.Content.Root.Groups[0].Groups[0]
+	if browser.Name != "Chrome" || browser.Groups[0].Name != "Default" {
+		t.Fatalf("got group %s/%s, want Chrome/Default", browser.Name, browser.Groups[0].Name)
+	}
+	entries := make(map[string]gokeepasslib.Entry)
+	for _, e := range browser.Groups[0].Entries {
+		entries[e.GetTitle()] = e
+	}
+	login, card := entries["github.com"], entries["John Doe"]
+	if login.GetPassword() != "secret" || login.GetContent("URL") != "https://github.com/login" {
+		t.Errorf("got login %s %s, want secret https://github.com/login", login.GetPassword(), login.GetContent("URL"))
+	}
+	if card.GetPassword() != "4111111111111111" || card.GetContent("Expiration") != "1/2030" {
+		t.Errorf("got card %s %s, want 4111111111111111 1/2030", card.GetPassword(), card.GetContent("Expiration"))
+	}
+}
@@ -0,0 +1,56 @@
+package browingdata
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"hack-browser-data/internal/utils/fileutil"
+)
+
+// profileDirs are the dirs of browser profiles in LayoutProfile, which are shared by outputters so files of
+// a profile are in the same dir. Names of browsers and profiles are made safe, and a name colliding with another
+// one is suffixed with a counter, e.g. a:b and a?b are both a_b, and Profile and profile collide on
+// case-insensitive file systems.
+type profileDirs struct {
+	dirs map[[2]string]string
+	// used are the lower case dirs of browsers and profiles
+	used map[string]bool
+	// browsers are the dirs of browsers
+	browsers map[string]string
+}
+
+func newProfileDirs() *profileDirs {
+	return &profileDirs{
+		dirs:     make(map[[2]string]string),
+		used:     make(map[string]bool),
+		browsers: make(map[string]string),
+	}
+}
+
+// dir returns the dir of browser profile relative to output dir, e.g. Chrome/Default
+func (p *profileDirs) dir(browser, profile string) string {
+	key := [2]string{browser, profile}
+	if dir, ok := p.dirs[key]; ok {
+		return dir
+	}
+	browserDir, ok := p.browsers[browser]
+	if !ok {
+		browserDir = p.unique("", browser)
+		p.browsers[browser] = browserDir
+	}
+	dir := filepath.Join(browserDir, p.unique(browserDir, profile))
+	p.dirs[key] = dir
+	return dir
+}
+
+// unique returns the safe name of name in parent dir, which doesn't collide with used names
+func (p *profileDirs) unique(parent, name string) string {
+	base := fileutil.SafeName(name)
+	safe := base
+	for n := 2; p.used[strings.ToLower(filepath.Join(parent, safe))]; n++ {
+		safe = fmt.Sprintf("%s-%d", base, n)
+	}
+	p.used[strings.ToLower(filepath.Join(parent, safe))] = true
+	return safe
+}
@@ -0,0 +1,32 @@
+package browingdata
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileDirs(t *testing.T) {
+	t.Parallel()
+
+	dirs := newProfileDirs()
+	testCases := []struct {
+		browser, profile string
+		want             string
+	}{
+		{"Chrome", "Default", "Chrome/Default"},
+		{"Chrome", "a:b", "Chrome/a_b"},
+		{"Chrome", "a?b", "Chrome/a_b-2"},
+		{"Chrome", "Profile", "Chrome/Profile"},
+		{"Chrome", "profile", "Chrome/profile-2"},
+		{"Chrome", "CON", "Chrome/CON_"},
+		// the same profile is in the same dir
+		{"Chrome", "a?b", "Chrome/a_b-2"},
+		{"Edge", "Default", "Edge/Default"},
+		{"chrome", "Default", "chrome-2/Default"},
+	}
+	for _, tc := range testCases {
+		if got := dirs.dir(tc.browser, tc.profile); got != filepath.FromSlash(tc.want) {
+			t.Errorf("dir(%s, %s) = %s, want %s", tc.browser, tc.profile, got, tc.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package localstorage
 import (
 	"bytes"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
@@ -17,10 +18,12 @@ import (
 type ChromiumLocalStorage []storage
 
 type storage struct {
-	IsMeta bool
-	URL    string
-	Key    string
-	Value  string
+	IsMeta bool   `json:"is_meta" csv:"is_meta"`
+	URL    string `json:"url" csv:"url"`
+	Key    string `json:"key" csv:"key"`
+	Value  string `json:"value" csv:"value"`
+	// Provenance is the LevelDB key or the row of storage
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 func (c *ChromiumLocalStorage) Parse(masterKey []byte) error {
@@ -40,7 +43,7 @@ func (c *ChromiumLocalStorage) Parse(masterKey []byte) error {
 		if len(value) > 1024*5 {
 			continue
 		}
-		s := new(storage)
+		s := &storage{Provenance: item.KeyProvenance(hex.EncodeToString(key))}
 		s.fillKey(key)
 		s.fillValue(value)
 		// don't save meta data
@@ -93,7 +96,7 @@ func (s *storage) fillValue(b []byte) {
 type FirefoxLocalStorage []storage
 
 const (
-	queryFirefoxHistory = `SELECT originKey, key, value FROM webappsstore2`
+	queryFirefoxHistory = `SELECT rowid, originKey, key, value FROM webappsstore2`
 	closeJournalMode    = `PRAGMA journal_mode=off`
 )
 
@@ -118,11 +121,14 @@ func (f *FirefoxLocalStorage) Parse(masterKey []byte) error {
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var originKey, key, value string
-		if err = rows.Scan(&originKey, &key, &value); err != nil {
+		var (
+			rowid                 int64
+			originKey, key, value string
+		)
+		if err = rows.Scan(&rowid, &originKey, &key, &value); err != nil {
 			log.Warn(err)
 		}
-		s := new(storage)
+		s := &storage{Provenance: item.RowProvenance("webappsstore2", rowid)}
 		s.fillFirefox(originKey, key, value)
 		*f = append(*f, *s)
 	}
@@ -0,0 +1,164 @@
+package browingdata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+const indexFile = "index.json"
+
+// manifest is the index of output dir, it lists browser profiles and every file written by outputters
+type manifest struct {
+	SchemaVersion string             `json:"schema_version"`
+	ToolVersion   string             `json:"tool_version"`
+	Hostname      string             `json:"hostname"`
+	User          string             `json:"user"`
+	GeneratedAt   time.Time          `json:"generated_at"`
+	Layout        string             `json:"layout"`
+	Profiles      []*manifestProfile `json:"profiles"`
+	Files         []*manifestFile    `json:"files"`
+}
+
+type manifestProfile struct {
+	Name        string `json:"name"`
+	Browser     string `json:"browser"`
+	Profile     string `json:"profile"`
+	ProfilePath string `json:"profile_path"`
+	// Dir is the dir of files of profile in output dir, which is only set in LayoutProfile
+	Dir string `json:"dir,omitempty"`
+	// Records are the number of records of each artifact
+	Records map[string]int `json:"records"`
+}
+
+// manifestFile is a written file, browser, profile and artifact are empty if it merges all browsers
+type manifestFile struct {
+	Path     string `json:"path"`
+	Format   string `json:"format"`
+	Browser  string `json:"browser,omitempty"`
+	Profile  string `json:"profile,omitempty"`
+	Artifact string `json:"artifact,omitempty"`
+	Records  int    `json:"records,omitempty"`
+	Size     int64  `json:"size"`
+}
+
+// manifestOutputter collects browser profiles and files written by other outputters, and writes them
+// as index.json when it's closed if manifest is enabled, so it must be closed after other outputters.
+type manifestOutputter struct {
+	host     envelopeHost
+	options  OutputOptions
+	profiles []*manifestProfile
+	files    []*manifestFile
+	// dirs are the dirs of browser profiles in LayoutProfile
+	dirs *profileDirs
+	// written is whether index.json is written
+	written bool
+}
+
+func newManifestOutputter(options OutputOptions) *manifestOutputter {
+	return &manifestOutputter{host: newEnvelopeHost(), options: options}
+}
+
+func (m *manifestOutputter) Write(_, browserName string, data *Data) error {
+	p := &manifestProfile{
+		Name:        browserName,
+		Browser:     data.browser,
+		Profile:     data.profile,
+		ProfilePath: data.profilePath,
+		Records:     make(map[string]int),
+	}
+	if m.dirs != nil {
+		p.Dir = filepath.ToSlash(m.dirs.dir(data.browser, data.profile))
+	}
+	for _, source := range data.sources {
+		if source.Length() > 0 {
+			p.Records[typeutil.SnakeCase(source.Name())] = source.Length()
+		}
+	}
+	m.profiles = append(m.profiles, p)
+	return nil
+}
+
+// addFile adds the file written into dir, data and source are nil if the file merges all browsers
+func (m *manifestOutputter) addFile(dir, filename, format string, data *Data, source Source) {
+	if dir == StdoutDir {
+		return
+	}
+	f := &manifestFile{Path: filepath.ToSlash(filename), Format: format}
+	if data != nil {
+		f.Browser, f.Profile = data.browser, data.profile
+	}
+	if source != nil {
+		f.Artifact, f.Records = typeutil.SnakeCase(source.Name()), source.Length()
+	}
+	if info, err := os.Stat(filepath.Join(dir, filename)); err == nil {
+		f.Size = info.Size()
+	}
+	m.files = append(m.files, f)
+}
+
+func (m *manifestOutputter) Close(dir string) error {
+	if !m.options.Manifest {
+		return nil
+	}
+	if err := m.writeManifest(dir); err != nil {
+		return err
+	}
+	m.written = true
+	log.Noticef("output to file %s success", outputPath(dir, indexFile))
+	return nil
+}
+
+// paths returns the paths of written files, which include index.json if it's written
+func (m *manifestOutputter) paths() []string {
+	paths := make([]string, 0, len(m.files)+1)
+	for _, f := range m.files {
+		paths = append(paths, f.Path)
+	}
+	if m.written {
+		paths = append(paths, indexFile)
+	}
+	return paths
+}
+
+func (m *manifestOutputter) writeManifest(dir string) error {
+	if dir == StdoutDir {
+		return errors.New("manifest can't be written to stdout")
+	}
+	layout := m.options.Layout
+	if layout == "" {
+		layout = LayoutFlat
+	}
+	sort.Slice(m.profiles, func(i, j int) bool { return m.profiles[i].Name < m.profiles[j].Name })
+	sort.Slice(m.files, func(i, j int) bool { return m.files[i].Path < m.files[j].Path })
+	index := manifest{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   m.options.ToolVersion,
+		Hostname:      m.host.hostname,
+		User:          m.host.user,
+		GeneratedAt:   time.Now(),
+		Layout:        layout,
+		Profiles:      m.profiles,
+		Files:         m.files,
+	}
+	if index.Profiles == nil {
+		index.Profiles = []*manifestProfile{}
+	}
+	if index.Files == nil {
+		index.Files = []*manifestFile{}
+	}
+	f, err := createFile(dir, indexFile)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, index); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
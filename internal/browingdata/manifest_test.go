@@ -0,0 +1,78 @@
+package browingdata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/item"
+)
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	source := &password.ChromiumPassword{}
+	testRecords(t, source, `[{"login_url":"https://github.com","user_name":"user","password":"secret"}]`)
+	data.sources[item.ChromiumPassword] = source
+	data.SetProfilePath(filepath.Join("google-chrome", "Default"))
+	data.SetPaths(map[item.Item]string{item.ChromiumPassword: filepath.Join("google-chrome", "Default", "Login Data")})
+
+	dir := t.TempDir()
+	filename := filepath.Join("Chrome", "Default", "password.csv")
+	f, err := createFile(dir, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("url,username\n")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m := newManifestOutputter(OutputOptions{ToolVersion: "1.0.0", Layout: LayoutProfile})
+	m.dirs = newProfileDirs()
+	if err := m.Write(dir, "chrome_default", data); err != nil {
+		t.Fatal(err)
+	}
+	m.addFile(dir, filename, "csv", data, source)
+	m.addFile(dir, reportFile, "html", nil, nil)
+	if err := m.writeManifest(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got manifest
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ToolVersion != "1.0.0" || got.Layout != LayoutProfile {
+		t.Errorf("got tool version %s and layout %s", got.ToolVersion, got.Layout)
+	}
+	wantProfiles := []*manifestProfile{{
+		Name:        "chrome_default",
+		Browser:     "Chrome",
+		Profile:     "Default",
+		ProfilePath: filepath.Join("google-chrome", "Default"),
+		Dir:         "Chrome/Default",
+		Records:     map[string]int{"password": 1},
+	}}
+	if !reflect.DeepEqual(got.Profiles, wantProfiles) {
+		t.Errorf("got profiles %+v, want %+v", got.Profiles[0], wantProfiles[0])
+	}
+	wantFiles := []*manifestFile{
+		{Path: "Chrome/Default/password.csv", Format: "csv", Browser: "Chrome", Profile: "Default", Artifact: "password", Records: 1, Size: 13},
+		{Path: reportFile, Format: "html"},
+	}
+	if !reflect.DeepEqual(got.Files, wantFiles) {
+		t.Errorf("got files %+v %+v, want %+v %+v", got.Files[0], got.Files[1], wantFiles[0], wantFiles[1])
+	}
+	if err := m.writeManifest(StdoutDir); err == nil {
+		t.Error("writeManifest() to stdout returned no error")
+	}
+}
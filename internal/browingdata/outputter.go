@@ -1,58 +1,428 @@
 package browingdata
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"hack-browser-data/internal/browingdata/bookmark"
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/fileutil"
+	"hack-browser-data/internal/utils/typeutil"
 
 	"github.com/gocarina/gocsv"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
-type OutPutter struct {
-	json bool
-	csv  bool
+// Outputter writes browsing data in an output format
+type Outputter interface {
+	// Write writes data of a browser, browserName is the name of browser and its profile
+	Write(dir, browserName string, data *Data) error
+	// Close is called after data of all browsers are written,
+	// outputters which merge all browsers into a single file write it here
+	Close(dir string) error
+}
+
+// NewOutputterFunc returns the Outputter of a format with options
+type NewOutputterFunc func(options OutputOptions) (Outputter, error)
+
+var outputters = make(map[string]NewOutputterFunc)
+
+// RegisterOutputter makes the Outputter of format available, it panics if format is registered twice
+func RegisterOutputter(format string, fn NewOutputterFunc) {
+	if _, ok := outputters[format]; ok {
+		panic("outputter of format " + format + " is already registered")
+	}
+	outputters[format] = fn
 }
 
-func NewOutPutter(flag string) *OutPutter {
-	o := &OutPutter{}
-	if flag == "json" {
-		o.json = true
-	} else {
-		o.csv = true
+// OutputFormats returns the sorted formats of all registered outputters
+func OutputFormats() []string {
+	formats := make([]string, 0, len(outputters))
+	for k := range outputters {
+		formats = append(formats, k)
 	}
-	return o
+	sort.Strings(formats)
+	return formats
 }
 
-func (o *OutPutter) Write(data Source, writer io.Writer) error {
-	switch o.json {
-	case true:
-		encoder := json.NewEncoder(writer)
-		encoder.SetIndent("  ", "  ")
-		encoder.SetEscapeHTML(false)
-		return encoder.Encode(data)
+// NewOutputters returns outputters of formats, it returns an error for unknown or duplicated format
+func NewOutputters(formats []string, options OutputOptions) ([]Outputter, error) {
+	if len(formats) == 0 {
+		return nil, errors.New("no output format")
+	}
+	switch options.Layout {
+	case "", LayoutFlat, LayoutProfile:
 	default:
-		gocsv.SetCSVWriter(func(w io.Writer) *gocsv.SafeCSVWriter {
-			writer := csv.NewWriter(transform.NewWriter(w, unicode.UTF8BOM.NewEncoder()))
-			writer.Comma = ','
-			return gocsv.NewSafeCSVWriter(writer)
+		return nil, fmt.Errorf("unknown layout %s, available layouts: %s|%s", options.Layout, LayoutFlat, LayoutProfile)
+	}
+	var dirs *profileDirs
+	if options.Layout == LayoutProfile {
+		dirs = newProfileDirs()
+	}
+	// manifest collects written files to compress them, index.json is only written if it's enabled
+	manifest := newManifestOutputter(options)
+	manifest.dirs = dirs
+	var evidence *evidenceOutputter
+	if options.Evidence {
+		evidence = newEvidenceOutputter(options)
+		evidence.manifest = manifest
+	}
+	seen := make(map[string]bool)
+	var result []Outputter
+	for _, format := range formats {
+		fn, ok := outputters[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown output format %s, available formats: %v", format, OutputFormats())
+		}
+		if seen[format] {
+			return nil, fmt.Errorf("duplicated output format %s", format)
+		}
+		seen[format] = true
+		o, err := fn(options)
+		if err != nil {
+			return nil, fmt.Errorf("%s format: %w", format, err)
+		}
+		switch o := o.(type) {
+		case *fileOutputter:
+			o.format, o.dirs, o.manifest = format, dirs, manifest
+		case *mergedOutputter:
+			o.format, o.manifest = format, manifest
+		}
+		result = append(result, o)
+	}
+	if options.Merge && !seen["netscape"] {
+		return nil, errors.New("merge is only supported by netscape format")
+	}
+	if evidence != nil {
+		result = append(result, evidence)
+	}
+	// manifest is the last outputter, which is closed after all files are written
+	return append(result, manifest), nil
+}
+
+// OutputFiles returns the slash separated paths relative to output dir of files written by outputters
+func OutputFiles(outputters []Outputter) []string {
+	for _, o := range outputters {
+		if m, ok := o.(*manifestOutputter); ok {
+			return m.paths()
+		}
+	}
+	return nil
+}
+
+// OutputOptions are options of output formats
+type OutputOptions struct {
+	// BookmarkTree outputs bookmarks as nested tree in json format
+	BookmarkTree bool
+	// Merge merges bookmarks of all browsers into a single file in netscape format
+	Merge bool
+	// CookieDomains only outputs cookies of these domains and their subdomains in cookies-txt format
+	CookieDomains []string
+	// KDBXPassword and KDBXKeyFile protect the KeePass database of kdbx format, at least one of them is required
+	KDBXPassword string
+	KDBXKeyFile  string
+	// KDBXCards also writes credit cards into the KeePass database of kdbx format
+	KDBXCards bool
+	// ToolVersion is the version of tool recorded in the envelope of json format
+	ToolVersion string
+	// Layout is the layout of files in output dir, files of each browser profile are in their own dir in LayoutProfile
+	Layout string
+	// Manifest writes an index of written files and browser profiles as index.json into output dir
+	Manifest bool
+	// Evidence writes hashes, sizes and MAC times of source files and their copies as manifest.json into output dir
+	Evidence bool
+	// Strict is recorded in the evidence manifest, browsers are refused if their sources are changed during copy
+	Strict bool
+	// Template is the text/template file of template format, which is executed for each artifact
+	Template string
+}
+
+// treeSource is implemented by sources which could be output as nested tree, e.g. bookmarks
+type treeSource interface {
+	Tree() []*bookmark.Node
+}
+
+// cookieJarSource is implemented by cookies which could be output as Netscape cookies.txt
+type cookieJarSource interface {
+	WriteCookieJar(w io.Writer, domains []string) error
+}
+
+// loginSource is implemented by passwords which could be output in the import formats of password managers
+type loginSource interface {
+	Logins() []password.Login
+}
+
+func init() {
+	RegisterOutputter("csv", func(options OutputOptions) (Outputter, error) {
+		// each file has its own BOM and header, so a single source could be written to stdout as parseable csv
+		return &fileOutputter{ext: "csv", singleStdout: true, write: func(w io.Writer, _ *Data, source Source) error {
+			return writeCSV(source, w)
+		}}, nil
+	})
+	RegisterOutputter("json", func(options OutputOptions) (Outputter, error) {
+		host := newEnvelopeHost()
+		return &fileOutputter{ext: "json", write: func(w io.Writer, data *Data, source Source) error {
+			return writeJSON(w, host.envelope(data, source, options))
+		}}, nil
+	})
+	RegisterOutputter("netscape", func(options OutputOptions) (Outputter, error) {
+		if options.Merge {
+			return &mergedOutputter{write: MergeBookmarks}, nil
+		}
+		return &fileOutputter{
+			ext:      "html",
+			supports: func(source Source) bool { _, ok := source.(treeSource); return ok },
+			write: func(w io.Writer, _ *Data, source Source) error {
+				return bookmark.WriteNetscape(w, source.(treeSource).Tree())
+			},
+		}, nil
+	})
+	RegisterOutputter("cookies-txt", func(options OutputOptions) (Outputter, error) {
+		return &fileOutputter{
+			ext:      "txt",
+			supports: func(source Source) bool { _, ok := source.(cookieJarSource); return ok },
+			write: func(w io.Writer, _ *Data, source Source) error {
+				return source.(cookieJarSource).WriteCookieJar(w, options.CookieDomains)
+			},
+		}, nil
+	})
+	for _, format := range password.ManagerFormats() {
+		format := format
+		RegisterOutputter(format, func(options OutputOptions) (Outputter, error) {
+			return &mergedOutputter{write: func(dir string, browsers map[string]*Data) ([]string, error) {
+				return MergePasswords(dir, format, browsers)
+			}}, nil
 		})
-		return gocsv.Marshal(data, writer)
 	}
 }
 
-func (o *OutPutter) CreateFile(dir, filename string) (*os.File, error) {
+// fileOutputter writes each source of a browser into its own file
+type fileOutputter struct {
+	ext string
+	// supports reports whether source could be written in the format, all sources are supported if it's nil
+	supports func(source Source) bool
+	// write writes source of data to w
+	write func(w io.Writer, data *Data, source Source) error
+	// singleStdout rejects sources written to StdoutDir after the first one
+	singleStdout bool
+	stdoutFile   string
+
+	// format, dirs and manifest are set by NewOutputters, dirs is nil in LayoutFlat
+	format   string
+	dirs     *profileDirs
+	manifest *manifestOutputter
+}
+
+func (o *fileOutputter) Write(dir, browserName string, data *Data) error {
+	var errs []error
+	for _, source := range data.sources {
+		if source.Length() == 0 {
+			// if the length of the export data is 0, then it is not necessary to output
+			continue
+		}
+		if o.supports != nil && !o.supports(source) {
+			continue
+		}
+		filename := fileutil.ItemName(browserName, source.Name(), o.ext)
+		if o.dirs != nil {
+			filename = filepath.Join(o.dirs.dir(data.browser, data.profile), typeutil.SnakeCase(source.Name())+"."+o.ext)
+		}
+		if o.singleStdout && dir == StdoutDir {
+			if o.stdoutFile != "" {
+				errs = append(errs, fmt.Errorf("%s is not written, %s writes only %s to stdout, use a results dir for multiple sources",
+					filename, o.format, o.stdoutFile))
+				continue
+			}
+			o.stdoutFile = filename
+		}
+
+		f, err := createFile(dir, filename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("create file %s error %w", filename, err))
+			continue
+		}
+		if err := o.write(f, data, source); err != nil {
+			f.Close()
+			errs = append(errs, fmt.Errorf("write to file %s error %w", filename, err))
+			continue
+		}
+		if err := f.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close file %s error %w", filename, err))
+			continue
+		}
+		log.Noticef("output to file %s success", outputPath(dir, filename))
+		if o.manifest != nil {
+			o.manifest.addFile(dir, filename, o.format, data, source)
+		}
+	}
+	return joinErrors(errs)
+}
+
+// outputErrors are the errors of files failed to write, other files are still written
+type outputErrors []error
+
+func (e outputErrors) Error() string {
+	s := make([]string, 0, len(e))
+	for _, err := range e {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+func (e outputErrors) Unwrap() []error {
+	return e
+}
+
+// joinErrors returns nil if there are no errors, and the only error if there is one
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return outputErrors(errs)
+	}
+}
+
+func (o *fileOutputter) Close(string) error {
+	return nil
+}
+
+// mergedOutputter collects data of all browsers, and writes them into a single file when it's closed
+type mergedOutputter struct {
+	browsers map[string]*Data
+	// write writes browsers into files in dir, and returns the names of written files
+	write func(dir string, browsers map[string]*Data) ([]string, error)
+
+	// format and manifest are set by NewOutputters
+	format   string
+	manifest *manifestOutputter
+}
+
+func (o *mergedOutputter) Write(_, browserName string, data *Data) error {
+	if o.browsers == nil {
+		o.browsers = make(map[string]*Data)
+	}
+	o.browsers[browserName] = data
+	return nil
+}
+
+func (o *mergedOutputter) Close(dir string) error {
+	files, err := o.write(dir, o.browsers)
+	if o.manifest != nil {
+		for _, filename := range files {
+			o.manifest.addFile(dir, filename, o.format, nil, nil)
+		}
+	}
+	return err
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("  ", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+func writeCSV(source Source, w io.Writer) error {
+	gocsv.SetCSVWriter(func(w io.Writer) *gocsv.SafeCSVWriter {
+		writer := csv.NewWriter(transform.NewWriter(w, unicode.UTF8BOM.NewEncoder()))
+		writer.Comma = ','
+		return gocsv.NewSafeCSVWriter(writer)
+	})
+	records, typ, ok := sourceRecords(source)
+	if !ok {
+		return gocsv.Marshal(source, w)
+	}
+	provenance := tableFields(records, typ)[len(recordFields(typ)):]
+	if len(provenance) == 0 {
+		return gocsv.Marshal(source, w)
+	}
+	return writeCSVProvenance(source, w, records, provenance)
+}
+
+// writeCSVProvenance writes records with provenance as provenance_* columns after the columns of record fields,
+// which are the same as table formats.
+func writeCSVProvenance(source Source, w io.Writer, records reflect.Value, provenance []reflect.StructField) error {
+	var b bytes.Buffer
+	if err := gocsv.MarshalCSV(source, gocsv.NewSafeCSVWriter(csv.NewWriter(&b))); err != nil {
+		return err
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(transform.NewWriter(w, unicode.UTF8BOM.NewEncoder()))
+	for i, row := range rows {
+		for _, f := range provenance {
+			switch {
+			case i == 0:
+				row = append(row, fieldName(f))
+			case recordProvenance(record(records, i-1)) == nil:
+				row = append(row, "")
+			default:
+				// zero values are omitted as in json, e.g. rowid of records read from LevelDB
+				v := record(records, i-1).FieldByIndex(f.Index)
+				if v.IsZero() {
+					row = append(row, "")
+				} else {
+					row = append(row, fmt.Sprint(v.Interface()))
+				}
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+const (
+	// LayoutFlat writes files of all browser profiles into output dir, e.g. chrome_default_password.csv
+	LayoutFlat = "flat"
+	// LayoutProfile writes files of each browser profile into <browser>/<profile>/<artifact>.<ext> of output dir
+	LayoutProfile = "profile"
+)
+
+// StdoutDir is the output dir which writes all outputs to stdout instead of files
+const StdoutDir = "-"
+
+// stdout is the output file of StdoutDir, which is not closed after writing
+type stdout struct {
+	io.Writer
+}
+
+func (stdout) Close() error {
+	return nil
+}
+
+func createFile(dir, filename string) (io.WriteCloser, error) {
 	if filename == "" {
 		return nil, errors.New("empty filename")
 	}
+	if dir == StdoutDir {
+		return stdout{os.Stdout}, nil
+	}
 
-	if dir != "" {
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			err := os.MkdirAll(dir, 0o750)
+	p := filepath.Join(dir, filename)
+	// filename may be in sub dir of dir
+	if parent := filepath.Dir(p); parent != "" {
+		if _, err := os.Stat(parent); os.IsNotExist(err) {
+			err := os.MkdirAll(parent, 0o750)
 			if err != nil {
 				return nil, err
 			}
@@ -61,7 +431,6 @@ func (o *OutPutter) CreateFile(dir, filename string) (*os.File, error) {
 
 	var file *os.File
 	var err error
-	p := filepath.Join(dir, filename)
 	file, err = os.OpenFile(filepath.Clean(p), os.O_TRUNC|os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
 	if err != nil {
 		return nil, err
@@ -69,9 +438,10 @@ func (o *OutPutter) CreateFile(dir, filename string) (*os.File, error) {
 	return file, nil
 }
 
-func (o *OutPutter) Ext() string {
-	if o.json {
-		return "json"
+// outputPath returns the path of output file for logging
+func outputPath(dir, filename string) string {
+	if dir == StdoutDir {
+		return "stdout"
 	}
-	return "csv"
+	return path.Join(dir, filename)
 }
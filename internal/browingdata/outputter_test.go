@@ -1,23 +1,170 @@
 package browingdata
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/fileutil"
 )
 
-func TestNewOutPutter(t *testing.T) {
+func TestMain(m *testing.M) {
+	// outputters log written files
+	log.SetOutput(io.Discard)
+	log.Init("notice")
+	os.Exit(m.Run())
+}
+
+// testRecords fills source with records in json, so tests use the record types of sources
+func testRecords(t *testing.T, source Source, records string) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(records), source); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewOutputters(t *testing.T) {
 	t.Parallel()
-	out := NewOutPutter("json")
-	if out == nil {
-		t.Error("New() returned nil")
+
+	testCases := []struct {
+		formats []string
+		options OutputOptions
+		want    int
+		wantErr bool
+	}{
+		{[]string{"csv", "json"}, OutputOptions{}, 3, false},
+		{[]string{"netscape"}, OutputOptions{Merge: true}, 2, false},
+		{[]string{"xml"}, OutputOptions{}, 0, true},
+		{[]string{"csv", "csv"}, OutputOptions{}, 0, true},
+		{[]string{"csv"}, OutputOptions{Merge: true}, 0, true},
+		{[]string{"kdbx"}, OutputOptions{}, 0, true},
+		{[]string{"template"}, OutputOptions{}, 0, true},
+		{[]string{"csv", "json"}, OutputOptions{Layout: LayoutProfile, Manifest: true}, 3, false},
+		{[]string{"csv"}, OutputOptions{Manifest: true, Evidence: true, Strict: true}, 3, false},
+		{[]string{"csv"}, OutputOptions{Evidence: true, Strict: true}, 3, false},
+		{[]string{"csv"}, OutputOptions{Layout: "tree"}, 0, true},
+		{nil, OutputOptions{}, 0, true},
+	}
+	for _, tc := range testCases {
+		got, err := NewOutputters(tc.formats, tc.options)
+		if (err != nil) != tc.wantErr || len(got) != tc.want {
+			t.Errorf("NewOutputters(%v) = %d outputters, error %v, want %d outputters, error %v", tc.formats, len(got), err, tc.want, tc.wantErr)
+		}
 	}
-	f, err := out.CreateFile("results", "test.json")
+}
+
+func TestCreateFile(t *testing.T) {
+	t.Parallel()
+	f, err := createFile("results", "test.json")
 	if err != nil {
-		t.Error("CreateFile() returned an error", err)
+		t.Error("createFile() returned an error", err)
 	}
 	defer os.RemoveAll("results")
-	err = out.Write(nil, f)
+	err = writeJSON(f, nil)
 	if err != nil {
-		t.Error("Write() returned an error", err)
+		t.Error("writeJSON() returned an error", err)
+	}
+}
+
+func TestFileOutputterErrors(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	data.sources[item.ChromiumPassword] = &testLogins{{URL: "https://github.com"}}
+	data.sources[item.ChromiumCreditCard] = &testCards{{Name: "card"}}
+	errWrite := errors.New("disk full")
+	o := &fileOutputter{ext: "txt", write: func(w io.Writer, _ *Data, source Source) error {
+		if source.Name() == "password" {
+			return errWrite
+		}
+		_, err := io.WriteString(w, source.Name())
+		return err
+	}}
+	dir := t.TempDir()
+	err := o.Write(dir, "chrome_default", data)
+	if !errors.Is(err, errWrite) {
+		t.Errorf("Write() error %v, want %s", err, errWrite)
+	}
+	if !fileutil.FileExists(filepath.Join(dir, "chrome_default_creditcard.txt")) {
+		t.Error("Write() didn't write other files after an error")
+	}
+
+	data.sources[item.ChromiumCreditCard] = &testLogins{{URL: "https://github.com"}}
+	err = o.Write(dir, "chrome_default", data)
+	var errs outputErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("Write() error %v, want 2 errors", err)
+	}
+}
+
+// TestCSVStdout isn't parallel, as it replaces os.Stdout
+func TestCSVStdout(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stdout := os.Stdout
+	os.Stdout = f
+	defer func() { os.Stdout = stdout }()
+
+	data := New("Chrome", "Default", nil)
+	data.sources[item.ChromiumPassword] = &testLogins{{URL: "https://github.com/login", UserName: "user"}}
+	data.sources[item.ChromiumCreditCard] = &testCards{{Name: "card"}}
+	outputters, err := NewOutputters([]string{"csv"}, OutputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outputters[0].Write(StdoutDir, "chrome_default", data); err == nil {
+		t.Error("Write() of multiple sources to stdout returned no error")
+	}
+	os.Stdout = stdout
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the first source is written, which is a single csv with one BOM and header
+	if strings.Count(string(b), "\ufeff") != 1 {
+		t.Errorf("got %d BOMs in stdout, want 1", strings.Count(string(b), "\ufeff"))
+	}
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(b), "\ufeff"))).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Errorf("got %d rows of csv in stdout, error %v, want header and a record", len(rows), err)
+	}
+}
+
+func TestOutputFiles(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	data.sources[item.ChromiumPassword] = &testLogins{{URL: "https://github.com/login", UserName: "user"}}
+	for _, manifest := range []bool{false, true} {
+		outputters, err := NewOutputters([]string{"csv", "sqlite"}, OutputOptions{Layout: LayoutProfile, Manifest: manifest})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		data.Output(dir, "chrome_default", outputters)
+		for _, o := range outputters {
+			if err := o.Close(dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want := []string{"Chrome/Default/password.csv", sqliteFile}
+		if manifest {
+			want = append(want, indexFile)
+		}
+		if got := OutputFiles(outputters); !reflect.DeepEqual(got, want) {
+			t.Errorf("OutputFiles() with manifest %t = %v, want %v", manifest, got, want)
+		}
 	}
 }
@@ -0,0 +1,204 @@
+package browingdata
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"hack-browser-data/internal/log"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const parquetExt = "parquet"
+
+func init() {
+	RegisterOutputter("parquet", func(options OutputOptions) (Outputter, error) {
+		return &mergedOutputter{write: WriteParquet}, nil
+	})
+}
+
+// WriteParquet writes each artifact of all browsers into a Parquet file with browser and profile columns,
+// fields keep their types as logical types of Parquet, such as timestamp, int64 and boolean.
+func WriteParquet(dir string, browsers map[string]*Data) ([]string, error) {
+	if dir == StdoutDir {
+		return nil, errors.New("parquet files can't be written to stdout")
+	}
+	var files []string
+	for _, t := range parquetTables(browsers) {
+		filename := t.name + "." + parquetExt
+		f, err := createFile(dir, filename)
+		if err != nil {
+			return files, err
+		}
+		if err := t.write(f); err != nil {
+			f.Close()
+			return files, fmt.Errorf("write %s error %w", filename, err)
+		}
+		if err := f.Close(); err != nil {
+			return files, err
+		}
+		log.Noticef("output to file %s success", outputPath(dir, filename))
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// parquetKind is the physical and logical type of column
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetBool
+	parquetInt
+	parquetDouble
+	parquetBytes
+	parquetTimestamp
+)
+
+// parquetKindOf returns the column kind of field type, types without a Parquet counterpart are stored as json
+func parquetKindOf(typ reflect.Type) parquetKind {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return parquetTimestamp
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return parquetBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return parquetInt
+	case reflect.Float32, reflect.Float64:
+		return parquetDouble
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return parquetBytes
+		}
+	}
+	return parquetString
+}
+
+// metadata returns the schema metadata of column in kind
+func (k parquetKind) metadata(name string, required bool) string {
+	var typ string
+	switch k {
+	case parquetBool:
+		typ = "type=BOOLEAN"
+	case parquetInt:
+		typ = "type=INT64"
+	case parquetDouble:
+		typ = "type=DOUBLE"
+	case parquetBytes:
+		typ = "type=BYTE_ARRAY"
+	case parquetTimestamp:
+		typ = "type=INT64, convertedtype=TIMESTAMP_MICROS, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS"
+	default:
+		typ = "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+	repetition := "OPTIONAL"
+	if required {
+		repetition = "REQUIRED"
+	}
+	return fmt.Sprintf("name=%s, %s, repetitiontype=%s", name, typ, repetition)
+}
+
+// parquetTable is the file of an artifact, whose columns are the union of fields of all its sources
+type parquetTable struct {
+	name    string
+	columns []*parquetColumn
+	sources []tableSource
+}
+
+type parquetColumn struct {
+	name string
+	kind parquetKind
+}
+
+func parquetTables(browsers map[string]*Data) []*parquetTable {
+	var result []*parquetTable
+	for _, rt := range recordTables(browsers) {
+		t := &parquetTable{name: rt.name, sources: rt.sources, columns: []*parquetColumn{
+			{name: "browser", kind: parquetString},
+			{name: "profile", kind: parquetString},
+		}}
+		for _, c := range rt.columns {
+			// a column whose fields have different types in sources falls back to string
+			kind := parquetKindOf(c.types[0])
+			for _, typ := range c.types[1:] {
+				if parquetKindOf(typ) != kind {
+					kind = parquetString
+				}
+			}
+			t.columns = append(t.columns, &parquetColumn{name: c.name, kind: kind})
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+func (t *parquetTable) write(w io.Writer) error {
+	metadata := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		metadata = append(metadata, c.kind.metadata(c.name, c.name == "browser" || c.name == "profile"))
+	}
+	pw, err := writer.NewCSVWriterFromWriter(metadata, w, 1)
+	if err != nil {
+		return err
+	}
+	for _, s := range t.sources {
+		for i := 0; i < s.records.Len(); i++ {
+			rec := record(s.records, i)
+			row := make([]interface{}, len(t.columns))
+			for j, c := range t.columns {
+				switch c.name {
+				case "browser":
+					row[j] = s.data.browser
+				case "profile":
+					row[j] = s.data.profile
+				default:
+					if index, ok := s.fields[c.name]; ok {
+						row[j] = parquetValue(rec.FieldByIndex(index), c.kind)
+					}
+				}
+			}
+			if err := pw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return pw.WriteStop()
+}
+
+// parquetValue converts field to the value of column kind, time is stored as microseconds since epoch in UTC
+// and nil is null
+func parquetValue(v reflect.Value, kind parquetKind) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if kind == parquetTimestamp {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil
+		}
+		return t.UnixMicro()
+	}
+	value := sqliteValue(v)
+	switch b := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		// byte array of Parquet is string in Go
+		return string(b)
+	}
+	if _, ok := value.(string); !ok && kind == parquetString {
+		return fmt.Sprint(value)
+	}
+	return value
+}
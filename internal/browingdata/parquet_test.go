@@ -0,0 +1,142 @@
+package browingdata
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/cookie"
+	"hack-browser-data/internal/browingdata/history"
+	"hack-browser-data/internal/item"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestParquetTable(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	chrome := New("Chrome", "Default", nil)
+	chrome.sources[item.ChromiumPassword] = &testLogins{
+		{URL: "https://github.com/login", UserName: "user", Password: "secret", CreateDate: created},
+	}
+	firefox := New("Firefox", "default-release", nil)
+	firefox.sources[item.FirefoxPassword] = &testLogins{
+		{URL: "https://example.com", UserName: "admin"},
+	}
+	tables := parquetTables(map[string]*Data{"chrome_default": chrome, "firefox_default_release": firefox})
+	if len(tables) != 1 || tables[0].name != "password" {
+		t.Fatalf("got %d tables, want a password table", len(tables))
+	}
+
+	var b bytes.Buffer
+	if err := tables[0].write(&b); err != nil {
+		t.Fatal(err)
+	}
+	f, err := buffer.NewBufferFile(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetColumnReader(f, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+	if pr.GetNumRows() != 2 {
+		t.Errorf("got %d rows, want 2", pr.GetNumRows())
+	}
+	for _, e := range pr.Footer.Schema {
+		if e.Name == "create_date" && (e.LogicalType == nil || !e.LogicalType.IsSetTIMESTAMP() || e.GetType() != parquet.Type_INT64) {
+			t.Errorf("got create_date of type %s, want timestamp", e.GetType())
+		}
+	}
+
+	values, _, _, err := pr.ReadColumnByPath("parquet_go_root"+common.PAR_GO_PATH_DELIMITER+"create_date", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[0] != created.UnixMicro() || values[1] != nil {
+		t.Errorf("got create_date %v, want [%d <nil>]", values, created.UnixMicro())
+	}
+	values, _, _, err = pr.ReadColumnByPath("parquet_go_root"+common.PAR_GO_PATH_DELIMITER+"browser", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[1] != "Firefox" {
+		t.Errorf("got browser %v, want [Chrome Firefox]", values)
+	}
+}
+
+// testPortCookies are cookies whose source port is a string, which falls back the column to string
+type testPortCookies []struct {
+	Host       string `json:"host"`
+	SourcePort string `json:"source_port"`
+}
+
+func (t *testPortCookies) Parse(_ []byte) error { return nil }
+func (t *testPortCookies) Name() string         { return "cookie" }
+func (t *testPortCookies) Length() int          { return len(*t) }
+
+func TestParquetColumnTypes(t *testing.T) {
+	t.Parallel()
+
+	chrome := New("Chrome", "Default", nil)
+	cookies, visits := &cookie.ChromiumCookie{}, &history.ChromiumVisit{}
+	testRecords(t, cookies, `[{"host":".github.com","key_name":"user_session","is_secure":true,"source_port":443,"expire_date":"2023-01-02T03:04:05Z"}]`)
+	testRecords(t, visits, `[{"id":42,"url":"https://github.com","visit_time":"2022-01-02T03:04:05Z","visit_duration_us":1500000,"typed_count":3}]`)
+	chrome.sources[item.ChromiumCookie] = cookies
+	chrome.sources[item.ChromiumVisit] = visits
+	other := New("Other", "Default", nil)
+	other.sources[item.FirefoxCookie] = &testPortCookies{{Host: "example.com", SourcePort: "unknown"}}
+	tables := parquetTables(map[string]*Data{"chrome_default": chrome, "other_default": other})
+	if len(tables) != 2 || tables[0].name != "cookie" || tables[1].name != "visit" {
+		t.Fatalf("got %d tables, want cookie and visit tables", len(tables))
+	}
+
+	testCases := []struct {
+		table  *parquetTable
+		column string
+		typ    parquet.Type
+		values []interface{}
+	}{
+		{tables[0], "is_secure", parquet.Type_BOOLEAN, []interface{}{true, nil}},
+		// source port is a string in the other browser
+		{tables[0], "source_port", parquet.Type_BYTE_ARRAY, []interface{}{"443", "unknown"}},
+		{tables[0], "expire_date", parquet.Type_INT64, []interface{}{time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), nil}},
+		{tables[1], "id", parquet.Type_INT64, []interface{}{int64(42)}},
+		{tables[1], "typed_count", parquet.Type_INT64, []interface{}{int64(3)}},
+		// duration is stored as microseconds as in other formats
+		{tables[1], "visit_duration_us", parquet.Type_INT64, []interface{}{int64(1500000)}},
+	}
+	for _, tc := range testCases {
+		var b bytes.Buffer
+		if err := tc.table.write(&b); err != nil {
+			t.Fatal(err)
+		}
+		f, err := buffer.NewBufferFile(b.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetColumnReader(f, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range pr.Footer.Schema {
+			if e.Name == tc.column && e.GetType() != tc.typ {
+				t.Errorf("got %s of type %s, want %s", tc.column, e.GetType(), tc.typ)
+			}
+		}
+		values, _, _, err := pr.ReadColumnByPath("parquet_go_root"+common.PAR_GO_PATH_DELIMITER+tc.column, int64(len(tc.values)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("got %s %v, want %v", tc.column, values, tc.values)
+		}
+		pr.ReadStop()
+	}
+}
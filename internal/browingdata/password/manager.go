@@ -0,0 +1,225 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Login is a decrypted login of browser, which is written in the import formats of password managers
+type Login struct {
+	// Browser is the browser and profile of login, it's used as the folder or group of password managers
+	Browser    string
+	URL        string
+	UserName   string
+	Password   string
+	CreateDate time.Time
+}
+
+func (c *ChromiumPassword) Logins() []Login {
+	return toLogins(*c)
+}
+
+func (c *YandexPassword) Logins() []Login {
+	return toLogins(*c)
+}
+
+func (f *FirefoxPassword) Logins() []Login {
+	return toLogins(*f)
+}
+
+func toLogins(data []loginData) []Login {
+	logins := make([]Login, 0, len(data))
+	for _, v := range data {
+		logins = append(logins, Login{
+			URL:        v.LoginURL,
+			UserName:   v.UserName,
+			Password:   v.Password,
+			CreateDate: v.CreateDate,
+		})
+	}
+	return logins
+}
+
+type managerWriter struct {
+	ext   string
+	write func(w io.Writer, logins []Login) error
+}
+
+var managerWriters = map[string]managerWriter{
+	"bitwarden-json": {"json", writeBitwardenJSON},
+	"bitwarden-csv":  {"csv", writeBitwardenCSV},
+	"keepassxc-csv":  {"csv", writeKeePassXCCSV},
+	"1password-csv":  {"csv", write1PasswordCSV},
+	"chrome-csv":     {"csv", writeChromeCSV},
+}
+
+// IsManagerFormat reports whether format is an import format of password managers
+func IsManagerFormat(format string) bool {
+	_, ok := managerWriters[format]
+	return ok
+}
+
+// ManagerFormats returns all import formats of password managers
+func ManagerFormats() []string {
+	formats := make([]string, 0, len(managerWriters))
+	for k := range managerWriters {
+		formats = append(formats, k)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ManagerExt returns the file extension of format
+func ManagerExt(format string) string {
+	return managerWriters[format].ext
+}
+
+// WriteManager writes logins in the import format of password manager
+func WriteManager(w io.Writer, format string, logins []Login) error {
+	mw, ok := managerWriters[format]
+	if !ok {
+		return fmt.Errorf("unsupported password manager format %s", format)
+	}
+	return mw.write(w, logins)
+}
+
+// Name returns the hostname of login url as the title of login
+func (l Login) Name() string {
+	if u, err := url.Parse(l.URL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return l.URL
+}
+
+func writeCSV(w io.Writer, header []string, logins []Login, record func(l Login) []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, l := range logins {
+		if err := cw.Write(record(l)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeChromeCSV writes logins in the format of chrome://password-manager/settings
+func writeChromeCSV(w io.Writer, logins []Login) error {
+	header := []string{"name", "url", "username", "password", "note"}
+	return writeCSV(w, header, logins, func(l Login) []string {
+		return []string{l.Name(), l.URL, l.UserName, l.Password, ""}
+	})
+}
+
+// writeBitwardenCSV writes logins in the format of https://bitwarden.com/help/condition-bitwarden-import/
+func writeBitwardenCSV(w io.Writer, logins []Login) error {
+	header := []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"}
+	return writeCSV(w, header, logins, func(l Login) []string {
+		return []string{l.Browser, "", "login", l.Name(), "", "", "0", l.URL, l.UserName, l.Password, ""}
+	})
+}
+
+// writeKeePassXCCSV writes logins in the same format as KeePassXC exports
+func writeKeePassXCCSV(w io.Writer, logins []Login) error {
+	header := []string{"Group", "Title", "Username", "Password", "URL", "Notes", "TOTP", "Icon", "Last Modified", "Created"}
+	return writeCSV(w, header, logins, func(l Login) []string {
+		// the date of login without it is empty, rather than the zero time
+		var created string
+		if !l.CreateDate.IsZero() {
+			created = l.CreateDate.UTC().Format(time.RFC3339)
+		}
+		return []string{"Root/" + l.Browser, l.Name(), l.UserName, l.Password, l.URL, "", "", "0", created, created}
+	})
+}
+
+// write1PasswordCSV writes logins in the format of https://support.1password.com/import-csv/
+func write1PasswordCSV(w io.Writer, logins []Login) error {
+	header := []string{"title", "website", "username", "password", "notes"}
+	return writeCSV(w, header, logins, func(l Login) []string {
+		return []string{l.Name(), l.URL, l.UserName, l.Password, l.Browser}
+	})
+}
+
+type bitwardenExport struct {
+	Encrypted bool              `json:"encrypted"`
+	Folders   []bitwardenFolder `json:"folders"`
+	Items     []bitwardenItem   `json:"items"`
+}
+
+type bitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bitwardenItem struct {
+	ID       string         `json:"id"`
+	FolderID string         `json:"folderId"`
+	Type     int            `json:"type"`
+	Reprompt int            `json:"reprompt"`
+	Name     string         `json:"name"`
+	Notes    *string        `json:"notes"`
+	Favorite bool           `json:"favorite"`
+	Login    bitwardenLogin `json:"login"`
+}
+
+type bitwardenLogin struct {
+	URIs     []bitwardenURI `json:"uris"`
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	TOTP     *string        `json:"totp"`
+}
+
+type bitwardenURI struct {
+	Match *int   `json:"match"`
+	URI   string `json:"uri"`
+}
+
+// writeBitwardenJSON writes logins as unencrypted bitwarden json export, each browser is a folder
+func writeBitwardenJSON(w io.Writer, logins []Login) error {
+	const bitwardenLoginType = 1
+	export := bitwardenExport{
+		Folders: []bitwardenFolder{},
+		Items:   make([]bitwardenItem, 0, len(logins)),
+	}
+	folders := make(map[string]string)
+	for _, l := range logins {
+		folderID, ok := folders[l.Browser]
+		if !ok {
+			folderID = newUUID()
+			folders[l.Browser] = folderID
+			export.Folders = append(export.Folders, bitwardenFolder{ID: folderID, Name: l.Browser})
+		}
+		export.Items = append(export.Items, bitwardenItem{
+			ID:       newUUID(),
+			FolderID: folderID,
+			Type:     bitwardenLoginType,
+			Name:     l.Name(),
+			Login: bitwardenLogin{
+				URIs:     []bitwardenURI{{URI: l.URL}},
+				Username: l.UserName,
+				Password: l.Password,
+			},
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(export)
+}
+
+// newUUID returns a random version 4 uuid
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,73 @@
+package password
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLogins = []Login{
+	{Browser: "chrome_default", URL: "https://github.com/login", UserName: "user", Password: "pa,ss\"word", CreateDate: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)},
+	{Browser: "firefox_default", URL: "https://example.com", UserName: "admin", Password: "secret"},
+}
+
+func TestWriteManagerCSV(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{"chrome-csv", "name,url,username,password,note\ngithub.com,https://github.com/login,user,\"pa,ss\"\"word\",\n"},
+		{"1password-csv", "title,website,username,password,notes\ngithub.com,https://github.com/login,user,\"pa,ss\"\"word\",chrome_default\n"},
+		{"bitwarden-csv", "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\nchrome_default,,login,github.com,,,0,https://github.com/login,user,\"pa,ss\"\"word\",\n"},
+		{"keepassxc-csv", "Group,Title,Username,Password,URL,Notes,TOTP,Icon,Last Modified,Created\nRoot/chrome_default,github.com,user,\"pa,ss\"\"word\",https://github.com/login,,,0,2022-01-02T03:04:05Z,2022-01-02T03:04:05Z\n"},
+	}
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := WriteManager(&b, tc.format, testLogins[:1]); err != nil {
+			t.Fatalf("WriteManager(%s) error %s", tc.format, err)
+		}
+		if b.String() != tc.want {
+			t.Errorf("WriteManager(%s) = %q, want %q", tc.format, b.String(), tc.want)
+		}
+	}
+}
+
+func TestWriteKeePassXCCSVWithoutDate(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := WriteManager(&b, "keepassxc-csv", testLogins[1:]); err != nil {
+		t.Fatal(err)
+	}
+	want := "Group,Title,Username,Password,URL,Notes,TOTP,Icon,Last Modified,Created\nRoot/firefox_default,example.com,admin,secret,https://example.com,,,0,,\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteBitwardenJSON(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := WriteManager(&b, "bitwarden-json", testLogins); err != nil {
+		t.Fatal(err)
+	}
+	var export bitwardenExport
+	if err := json.Unmarshal([]byte(b.String()), &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Folders) != 2 || len(export.Items) != 2 {
+		t.Fatalf("got %d folders and %d items, want 2 and 2", len(export.Folders), len(export.Items))
+	}
+	for i, item := range export.Items {
+		if item.FolderID != export.Folders[i].ID || export.Folders[i].Name != testLogins[i].Browser {
+			t.Errorf("item %s is in folder %s, want %s", item.Name, item.FolderID, export.Folders[i].ID)
+		}
+		if item.Login.URIs[0].URI != testLogins[i].URL || item.Login.Password != testLogins[i].Password {
+			t.Errorf("item %s = %+v, want %+v", item.Name, item.Login, testLogins[i])
+		}
+	}
+}
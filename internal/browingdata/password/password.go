@@ -21,16 +21,18 @@ import (
 type ChromiumPassword []loginData
 
 type loginData struct {
-	UserName    string
+	UserName    string `json:"user_name" csv:"user_name"`
 	encryptPass []byte
 	encryptUser []byte
-	Password    string
-	LoginURL    string
-	CreateDate  time.Time
+	Password    string    `json:"password" csv:"password"`
+	LoginURL    string    `json:"login_url" csv:"login_url"`
+	CreateDate  time.Time `json:"create_date" csv:"create_date"`
+	// Provenance is the row of login, or the JSON pointer of login in logins.json of Firefox
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
 }
 
 const (
-	queryChromiumLogin = `SELECT origin_url, username_value, password_value, date_created FROM logins`
+	queryChromiumLogin = `SELECT rowid, origin_url, username_value, password_value, date_created FROM logins`
 )
 
 func (c *ChromiumPassword) Parse(masterKey []byte) error {
@@ -50,15 +52,16 @@ func (c *ChromiumPassword) Parse(masterKey []byte) error {
 		var (
 			url, username string
 			pwd, password []byte
-			create        int64
+			id, create    int64
 		)
-		if err := rows.Scan(&url, &username, &pwd, &create); err != nil {
+		if err := rows.Scan(&id, &url, &username, &pwd, &create); err != nil {
 			log.Warn(err)
 		}
 		login := loginData{
 			UserName:    username,
 			encryptPass: pwd,
 			LoginURL:    url,
+			Provenance:  item.RowProvenance("logins", id),
 		}
 		if len(pwd) > 0 {
 			var err error
@@ -97,7 +100,7 @@ func (c *ChromiumPassword) Length() int {
 type YandexPassword []loginData
 
 const (
-	queryYandexLogin = `SELECT action_url, username_value, password_value, date_created FROM logins`
+	queryYandexLogin = `SELECT rowid, action_url, username_value, password_value, date_created FROM logins`
 )
 
 func (c *YandexPassword) Parse(masterKey []byte) error {
@@ -117,15 +120,16 @@ func (c *YandexPassword) Parse(masterKey []byte) error {
 		var (
 			url, username string
 			pwd, password []byte
-			create        int64
+			id, create    int64
 		)
-		if err := rows.Scan(&url, &username, &pwd, &create); err != nil {
+		if err := rows.Scan(&id, &url, &username, &pwd, &create); err != nil {
 			log.Warn(err)
 		}
 		login := loginData{
 			UserName:    username,
 			encryptPass: pwd,
 			LoginURL:    url,
+			Provenance:  item.RowProvenance("logins", id),
 		}
 
 		if len(pwd) > 0 {
@@ -222,6 +226,7 @@ func (f *FirefoxPassword) Parse(masterKey []byte) error {
 					UserName:   string(user),
 					Password:   string(pwd),
 					CreateDate: v.CreateDate,
+					Provenance: v.Provenance,
 				})
 			}
 		}
@@ -259,13 +264,14 @@ func getFirefoxLoginData() (l []loginData, err error) {
 	defer os.Remove(item.TempFirefoxPassword)
 	h := gjson.GetBytes(s, "logins")
 	if h.Exists() {
-		for _, v := range h.Array() {
+		for i, v := range h.Array() {
 			var (
 				m    loginData
 				user []byte
 				pass []byte
 			)
 			m.LoginURL = v.Get("formSubmitURL").String()
+			m.Provenance = item.PointerProvenance(item.JSONPointer("logins", i))
 			user, err = base64.StdEncoding.DecodeString(v.Get("encryptedUsername").String())
 			if err != nil {
 				return nil, err
@@ -0,0 +1,47 @@
+package password
+
+import (
+	"database/sql"
+	"testing"
+
+	"hack-browser-data/internal/item"
+)
+
+// createLogins creates the logins table without id column, which isn't in every schema of logins
+func createLogins(t *testing.T, filename, urlColumn string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	stmts := []string{
+		"CREATE TABLE logins (" + urlColumn + " TEXT, username_value TEXT, password_value BLOB, date_created INTEGER)",
+		"INSERT INTO logins VALUES ('https://github.com/login', 'user', x'', 13288000000000000)",
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseLoginsWithoutID(t *testing.T) {
+	t.Parallel()
+
+	createLogins(t, item.TempChromiumPassword, "origin_url")
+	chromium := &ChromiumPassword{}
+	if err := chromium.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	createLogins(t, item.TempYandexPassword, "action_url")
+	yandex := &YandexPassword{}
+	if err := yandex.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, logins := range [][]loginData{*chromium, *yandex} {
+		if len(logins) != 1 || logins[0].UserName != "user" || logins[0].Provenance.RowID != 1 {
+			t.Errorf("got logins %+v, want a login of row 1", logins)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package browingdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+// snapshot is the hashes of the copy of item which source is parsed from, files of a copied folder
+// are keyed by their paths relative to the folder, and the copied file is keyed by empty string.
+// It's hashed before parsing as sources remove the copy after parsing.
+type snapshot map[string]string
+
+func hashSnapshot(name string) (snapshot, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		h, err := hashFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot{"": h}, nil
+	}
+	s := make(snapshot)
+	err = filepath.WalkDir(name, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(name, path)
+		if err != nil {
+			return err
+		}
+		s[rel], err = hashFile(path)
+		return err
+	})
+	return s, err
+}
+
+// hashFile returns the hex encoded SHA-256 of file
+func hashFile(name string) (string, error) {
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// setProvenance fills the source path, artifact and snapshot hash of provenance of records, whose locations
+// are filled by source. Provenance of records read from a folder has no hash if the file is unknown, e.g. LevelDB.
+func (d *Data) setProvenance(i item.Item, source Source, s snapshot) {
+	records, _, ok := sourceRecords(source)
+	if !ok {
+		return
+	}
+	sourcePath := d.paths[i]
+	if p, err := filepath.Abs(sourcePath); err == nil {
+		sourcePath = p
+	}
+	// records of a copied folder, whose source path is a file, are read from siblings of the file
+	isDir := false
+	if info, err := os.Stat(sourcePath); err == nil {
+		isDir = info.IsDir()
+	}
+	artifact := typeutil.SnakeCase(source.Name())
+	for j := 0; j < records.Len(); j++ {
+		v := record(records, j).FieldByName("Provenance")
+		if !v.IsValid() || v.Type() != provenanceType {
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.ValueOf(&item.Provenance{}))
+		}
+		p := v.Interface().(*item.Provenance)
+		p.Artifact = artifact
+		p.SourcePath = sourcePath
+		p.SHA256 = s[p.File]
+		switch {
+		case p.File == "":
+		case isDir:
+			p.SourcePath = filepath.Join(sourcePath, p.File)
+		default:
+			p.SourcePath = filepath.Join(filepath.Dir(sourcePath), p.File)
+		}
+	}
+}
+
+// clearProvenance removes the locations filled by source from its records, which are output only
+// if provenance is enabled
+func clearProvenance(source Source) {
+	records, _, ok := sourceRecords(source)
+	if !ok {
+		return
+	}
+	for j := 0; j < records.Len(); j++ {
+		v := record(records, j).FieldByName("Provenance")
+		if !v.IsValid() || v.Type() != provenanceType {
+			return
+		}
+		v.Set(reflect.Zero(provenanceType))
+	}
+}
@@ -0,0 +1,165 @@
+package browingdata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hack-browser-data/internal/item"
+)
+
+func TestSetProvenance(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	// the copy of Web Data, which is removed after parsing
+	snapshotPath := filepath.Join(dir, "creditCard")
+	if err := os.WriteFile(snapshotPath, []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := hashSnapshot(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := New("Chrome", "Default", nil)
+	source := &testCards{
+		{Name: "card", Provenance: item.RowProvenance("credit_cards", 7)},
+		{Name: "no provenance"},
+	}
+	data.sources[item.ChromiumCreditCard] = source
+	sourcePath := filepath.Join(dir, "Default", "Web Data")
+	data.SetPaths(map[item.Item]string{item.ChromiumCreditCard: sourcePath})
+	data.setProvenance(item.ChromiumCreditCard, source, snap)
+
+	want := item.Provenance{
+		SourcePath: sourcePath,
+		Artifact:   "creditcard",
+		Table:      "credit_cards",
+		RowID:      7,
+		// SHA-256 of test
+		SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	}
+	if got := (*source)[0].Provenance; got == nil || *got != want {
+		t.Errorf("got provenance %+v, want %+v", got, want)
+	}
+	if got := (*source)[1].Provenance; got == nil || got.SourcePath != sourcePath || got.RowID != 0 {
+		t.Errorf("got provenance %+v of record without location", got)
+	}
+
+	records, typ, _ := sourceRecords(source)
+	fields := tableFields(records, typ)
+	names := make(map[string]bool)
+	for _, f := range fields {
+		names[fieldName(f)] = true
+	}
+	for _, name := range []string{"card_number", "provenance_source_path", "provenance_rowid", "provenance_sha256"} {
+		if !names[name] {
+			t.Errorf("tableFields() has no field %s", name)
+		}
+	}
+	if names["provenance_file"] {
+		t.Error("tableFields() has field provenance_file")
+	}
+
+	clearProvenance(source)
+	for _, c := range *source {
+		if c.Provenance != nil {
+			t.Errorf("got provenance %+v after clearProvenance()", c.Provenance)
+		}
+	}
+	if got := tableFields(records, typ); len(got) != len(recordFields(typ)) {
+		t.Errorf("tableFields() without provenance returned %d fields, want %d", len(got), len(recordFields(typ)))
+	}
+}
+
+func TestSetProvenanceOfFolder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	snapshotPath := filepath.Join(dir, "extension")
+	manifest := filepath.Join("abc", "1.0", "manifest.json")
+	if err := os.MkdirAll(filepath.Join(snapshotPath, filepath.Dir(manifest)), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshotPath, manifest), []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := hashSnapshot(snapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// source path is a folder, so the file is joined to it
+	extensions := filepath.Join(dir, "Default", "Extensions")
+	if err := os.MkdirAll(extensions, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data := New("Chrome", "Default", nil)
+	source := &testCards{{Provenance: &item.Provenance{File: manifest}}}
+	data.SetPaths(map[item.Item]string{item.ChromiumExtension: extensions})
+	data.setProvenance(item.ChromiumExtension, source, snap)
+
+	p := (*source)[0].Provenance
+	if want := filepath.Join(extensions, manifest); p.SourcePath != want {
+		t.Errorf("got source path %s, want %s", p.SourcePath, want)
+	}
+	if p.SHA256 != snap[manifest] || p.SHA256 == "" {
+		t.Errorf("got sha256 %s, want %s", p.SHA256, snap[manifest])
+	}
+}
+
+func TestWriteCSVProvenance(t *testing.T) {
+	t.Parallel()
+
+	source := &testCards{
+		{Name: "card", Provenance: &item.Provenance{SourcePath: "Web Data", Artifact: "creditcard", Table: "credit_cards", RowID: 7}},
+		{Name: "leveldb", Provenance: &item.Provenance{SourcePath: "Local Storage", Artifact: "creditcard", Key: "5f"}},
+	}
+	var b bytes.Buffer
+	if err := writeCSV(source, &b); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(b.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header and 2 records", len(rows))
+	}
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	for _, c := range []struct {
+		row          int
+		column, want string
+	}{
+		{1, "name", "card"},
+		{1, "provenance_table", "credit_cards"},
+		{1, "provenance_rowid", "7"},
+		{2, "provenance_key", "5f"},
+		{2, "provenance_rowid", ""},
+	} {
+		i, ok := columns[c.column]
+		if !ok {
+			t.Errorf("got header %v, want column %s", rows[0], c.column)
+			continue
+		}
+		if got := rows[c.row][i]; got != c.want {
+			t.Errorf("got %s %q of row %d, want %q", c.column, got, c.row, c.want)
+		}
+	}
+
+	// records without provenance have no provenance columns
+	b.Reset()
+	if err := writeCSV(&testCards{{Name: "card"}}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "provenance_") {
+		t.Errorf("got provenance columns without provenance: %s", b.String())
+	}
+}
@@ -0,0 +1,110 @@
+package browingdata
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+// sourceRecords returns records of source and the struct type of record,
+// ok is false if source isn't a slice of structs or pointers of structs.
+func sourceRecords(source Source) (records reflect.Value, typ reflect.Type, ok bool) {
+	records = reflect.Indirect(reflect.ValueOf(source))
+	if records.Kind() != reflect.Slice {
+		return records, nil, false
+	}
+	typ = records.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return records, typ, typ.Kind() == reflect.Struct
+}
+
+// recordFields returns the exported fields of record type, provenance of record isn't one of them
+// as it's only filled if it's enabled, see tableFields.
+func recordFields(typ reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, f := range reflect.VisibleFields(typ) {
+		if f.IsExported() && !f.Anonymous && f.Type != provenanceType {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+var provenanceType = reflect.TypeOf(&item.Provenance{})
+
+// tableFields returns fields of records as columns of table formats, fields of provenance are
+// added as provenance_* columns if records carry provenance.
+func tableFields(records reflect.Value, typ reflect.Type) []reflect.StructField {
+	fields := recordFields(typ)
+	if records.Len() == 0 || recordProvenance(record(records, 0)) == nil {
+		return fields
+	}
+	p, _ := typ.FieldByName("Provenance")
+	for _, f := range recordFields(provenanceType.Elem()) {
+		name := fieldName(f)
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		f.Name = "Provenance" + f.Name
+		f.Tag = reflect.StructTag(`json:"provenance_` + name + `"`)
+		f.Index = append(append([]int{}, p.Index...), f.Index...)
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// recordProvenance returns the provenance of record, or nil if it has none
+func recordProvenance(rec reflect.Value) *item.Provenance {
+	if v := rec.FieldByName("Provenance"); v.IsValid() && v.Type() == provenanceType {
+		return v.Interface().(*item.Provenance)
+	}
+	return nil
+}
+
+// fieldName returns the name of field in outputs, which is the name of json tag,
+// or the snake case of field name if it has no json tag
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return typeutil.SnakeCase(f.Name)
+}
+
+// record returns the i-th record of records as struct value
+func record(records reflect.Value, i int) reflect.Value {
+	return reflect.Indirect(records.Index(i))
+}
+
+// recordTime returns the first non-zero time of record, expiry time isn't the time of record
+func recordTime(rec reflect.Value, fields []reflect.StructField) time.Time {
+	for _, f := range fields {
+		if f.Type != timeType || strings.Contains(f.Name, "Expire") {
+			continue
+		}
+		if t := rec.FieldByIndex(f.Index).Interface().(time.Time); !t.IsZero() && t.Unix() > 0 {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// recordString returns the string field of record, or empty string if there is no such field
+func recordString(rec reflect.Value, name string) string {
+	if v := rec.FieldByName(name); v.IsValid() && v.Kind() == reflect.String {
+		return v.String()
+	}
+	return ""
+}
+
+// recordTimeOf returns the time field of record, or zero time if there is no such field
+func recordTimeOf(rec reflect.Value, name string) time.Time {
+	if v := rec.FieldByName(name); v.IsValid() && v.Type() == timeType {
+		return v.Interface().(time.Time)
+	}
+	return time.Time{}
+}
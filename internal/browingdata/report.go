@@ -0,0 +1,217 @@
+package browingdata
+
+import (
+	// embed the template of html report
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+const (
+	reportFile = "report.html"
+	// maxTimelineEvents limits the size of timeline, the most recent events are kept
+	maxTimelineEvents = 5000
+	timeLayout        = "2006-01-02 15:04:05"
+)
+
+//go:embed report.html
+var reportTemplate string
+
+// secretFields are fields masked in report until clicked
+var secretFields = map[string]bool{
+	"Password":   true,
+	"Value":      true,
+	"CardNumber": true,
+}
+
+// titleFields are fields used as the title of event in timeline, in order of preference
+var titleFields = []string{"Title", "Name", "Term", "URL", "LoginURL", "Host", "TargetPath"}
+
+func init() {
+	RegisterOutputter("html", func(options OutputOptions) (Outputter, error) {
+		return &mergedOutputter{write: WriteReport}, nil
+	})
+}
+
+// WriteReport writes artifacts of all browsers into a single self-contained html report, which has
+// a summary of each browser profile, a sortable and filterable table of each artifact and a timeline.
+func WriteReport(dir string, browsers map[string]*Data) ([]string, error) {
+	r := newReport(browsers)
+	if len(r.Artifacts) == 0 {
+		return nil, nil
+	}
+	f, err := createFile(dir, reportFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.write(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	log.Noticef("output to file %s success", outputPath(dir, reportFile))
+	return []string{reportFile}, nil
+}
+
+type report struct {
+	Generated string
+	Hostname  string
+	Profiles  []*reportProfile
+	Artifacts []*reportArtifact
+	Timeline  []*reportEvent
+}
+
+type reportProfile struct {
+	Browser string
+	Profile string
+	Total   int
+	Counts  map[string]int
+}
+
+type reportArtifact struct {
+	Name    string
+	Columns []string
+	Rows    [][]reportCell
+}
+
+type reportCell struct {
+	Value  string
+	Secret bool
+}
+
+type reportEvent struct {
+	time     time.Time
+	Time     string
+	Browser  string
+	Profile  string
+	Artifact string
+	Title    string
+}
+
+func newReport(browsers map[string]*Data) *report {
+	hostname, _ := os.Hostname()
+	r := &report{Generated: time.Now().Format(timeLayout), Hostname: hostname}
+	artifacts := make(map[string]*reportArtifact)
+	names := typeutil.Keys(browsers)
+	sort.Strings(names)
+	for _, name := range names {
+		d := browsers[name]
+		p := &reportProfile{Browser: d.browser, Profile: d.profile, Counts: make(map[string]int)}
+		for _, source := range d.sources {
+			records, typ, ok := sourceRecords(source)
+			if !ok || records.Len() == 0 {
+				continue
+			}
+			a, ok := artifacts[source.Name()]
+			if !ok {
+				a = &reportArtifact{Name: source.Name()}
+				artifacts[source.Name()] = a
+			}
+			r.addRecords(a, d, source.Name(), records, typ)
+			p.Counts[source.Name()] += records.Len()
+			p.Total += records.Len()
+		}
+		if p.Total > 0 {
+			r.Profiles = append(r.Profiles, p)
+		}
+	}
+	for _, a := range artifacts {
+		r.Artifacts = append(r.Artifacts, a)
+	}
+	sort.Slice(r.Artifacts, func(i, j int) bool { return r.Artifacts[i].Name < r.Artifacts[j].Name })
+	sort.SliceStable(r.Timeline, func(i, j int) bool { return r.Timeline[i].time.After(r.Timeline[j].time) })
+	if len(r.Timeline) > maxTimelineEvents {
+		r.Timeline = r.Timeline[:maxTimelineEvents]
+	}
+	return r
+}
+
+func (r *report) addRecords(a *reportArtifact, d *Data, artifact string, records reflect.Value, typ reflect.Type) {
+	var fields []reflect.StructField
+	for _, f := range tableFields(records, typ) {
+		// browser and profile are the leading columns of all artifacts
+		if f.Name != "Browser" && f.Name != "Profile" {
+			fields = append(fields, f)
+		}
+	}
+	if a.Columns == nil {
+		a.Columns = []string{"browser", "profile"}
+		for _, f := range fields {
+			a.Columns = append(a.Columns, fieldName(f))
+		}
+	}
+	// records of different sources in the same artifact may have different fields
+	index := make(map[string]int, len(a.Columns))
+	for i, c := range a.Columns {
+		index[c] = i
+	}
+	for i := 0; i < records.Len(); i++ {
+		rec := record(records, i)
+		row := make([]reportCell, len(a.Columns))
+		row[0].Value, row[1].Value = d.browser, d.profile
+		for _, f := range fields {
+			if j, ok := index[fieldName(f)]; ok {
+				row[j] = reportCell{Value: reportValue(rec.FieldByIndex(f.Index)), Secret: secretFields[f.Name]}
+			}
+		}
+		a.Rows = append(a.Rows, row)
+		if e := newReportEvent(rec, fields); e != nil {
+			e.Browser, e.Profile, e.Artifact = d.browser, d.profile, artifact
+			r.Timeline = append(r.Timeline, e)
+		}
+	}
+}
+
+// newReportEvent returns the event of record at its time, records without time are not events
+func newReportEvent(rec reflect.Value, fields []reflect.StructField) *reportEvent {
+	t := recordTime(rec, fields)
+	if t.IsZero() {
+		return nil
+	}
+	e := &reportEvent{time: t, Time: t.Format(timeLayout)}
+	for _, name := range titleFields {
+		if s := recordString(rec, name); s != "" {
+			e.Title = s
+			break
+		}
+	}
+	return e
+}
+
+func reportValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == timeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(timeLayout)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return fmt.Sprintf("%x", v.Bytes())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func (r *report) write(w io.Writer) error {
+	t, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
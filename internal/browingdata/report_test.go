@@ -0,0 +1,48 @@
+package browingdata
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/password"
+	"hack-browser-data/internal/item"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	source := &password.ChromiumPassword{}
+	// times of report are local
+	testRecords(t, source, fmt.Sprintf(`[
+		{"login_url":"https://github.com/login","user_name":"user","password":"<secret>","create_date":%q},
+		{"login_url":"https://example.com","user_name":"admin","password":"hunter2","create_date":%q}]`,
+		time.Date(2022, 1, 2, 3, 4, 5, 0, time.Local).Format(time.RFC3339), time.Date(2023, 1, 2, 3, 4, 5, 0, time.Local).Format(time.RFC3339)))
+	data.sources[item.ChromiumPassword] = source
+	r := newReport(map[string]*Data{"chrome_default": data})
+	if len(r.Profiles) != 1 || r.Profiles[0].Counts["password"] != 2 {
+		t.Fatalf("got profiles %+v, want chrome default with 2 passwords", r.Profiles)
+	}
+	if len(r.Timeline) != 2 || r.Timeline[0].Title != "https://example.com" || r.Timeline[0].Time != "2023-01-02 03:04:05" {
+		t.Errorf("got timeline %+v, want the most recent event first", r.Timeline)
+	}
+
+	var b strings.Builder
+	if err := r.write(&b); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	for _, want := range []string{`data-secret="&lt;secret&gt;"`, `data-secret="hunter2"`, `<th>user_name</th>`, `<td>admin</td>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report doesn't contain %s", want)
+		}
+	}
+	if strings.Contains(html, "<secret>") || strings.Contains(html, "<td>hunter2</td>") {
+		t.Error("secret is not masked in report")
+	}
+	if strings.Contains(html, "http://") || strings.Contains(html, "src=") {
+		t.Error("report references external resources")
+	}
+}
@@ -0,0 +1,225 @@
+package browingdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/user"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/browingdata/bookmark"
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+// SchemaVersion is the version of output schema, it must be increased when the fields of records or envelope are changed.
+const SchemaVersion = "2"
+
+// envelope wraps records of an artifact in json format with where and when they are collected
+type envelope struct {
+	SchemaVersion string      `json:"schema_version"`
+	ToolVersion   string      `json:"tool_version"`
+	Hostname      string      `json:"hostname"`
+	User          string      `json:"user"`
+	Browser       string      `json:"browser"`
+	Profile       string      `json:"profile"`
+	Artifact      string      `json:"artifact"`
+	SourcePath    string      `json:"source_path"`
+	CollectedAt   time.Time   `json:"collected_at"`
+	Records       interface{} `json:"records"`
+}
+
+// bookmarkTreeArtifact is the artifact of bookmarks output as nested tree
+const bookmarkTreeArtifact = "bookmark_tree"
+
+// provenanceDef is the definition of provenance of records in JSON Schema
+const provenanceDef = "provenance"
+
+// envelopeHost is the hostname and OS user of envelopes
+type envelopeHost struct {
+	hostname string
+	user     string
+}
+
+func newEnvelopeHost() envelopeHost {
+	var h envelopeHost
+	h.hostname, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		h.user = u.Username
+	}
+	return h
+}
+
+func (h envelopeHost) envelope(data *Data, source Source, options OutputOptions) *envelope {
+	e := &envelope{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   options.ToolVersion,
+		Hostname:      h.hostname,
+		User:          h.user,
+		Browser:       data.browser,
+		Profile:       data.profile,
+		Artifact:      typeutil.SnakeCase(source.Name()),
+		SourcePath:    data.sourcePath(source),
+		CollectedAt:   data.collected,
+		Records:       source,
+	}
+	if t, ok := source.(treeSource); ok && options.BookmarkTree {
+		e.Artifact = bookmarkTreeArtifact
+		e.Records = t.Tree()
+	}
+	return e
+}
+
+// JSONSchema returns the JSON Schema of json format, records of each artifact are described in $defs.
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]map[string]interface{}), refs: make(map[reflect.Type]string)}
+	nodeType := reflect.TypeOf(bookmark.Node{})
+	g.refs[nodeType] = bookmarkTreeArtifact
+	g.defs[bookmarkTreeArtifact] = g.object(nodeType)
+	// provenance of all artifacts refers to the same definition, which isn't an artifact
+	provenance := g.object(provenanceType.Elem())
+	g.refs[provenanceType.Elem()] = provenanceDef
+
+	// sources of all items are created to get the record types of all artifacts
+	var items []item.Item
+	for i := item.ChromiumKey; i <= item.FirefoxContainer; i++ {
+		items = append(items, i)
+	}
+	d := New("", "", items)
+	for _, i := range items {
+		source, ok := d.sources[i]
+		if !ok {
+			continue
+		}
+		if _, typ, ok := sourceRecords(source); ok {
+			g.addRecord(typeutil.SnakeCase(source.Name()), typ)
+		}
+	}
+
+	artifacts := typeutil.Keys(g.defs)
+	sort.Strings(artifacts)
+	conditions := make([]interface{}, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{"properties": map[string]interface{}{"artifact": map[string]interface{}{"const": artifact}}},
+			"then": map[string]interface{}{"properties": map[string]interface{}{"records": map[string]interface{}{
+				"items": map[string]interface{}{"$ref": "#/$defs/" + artifact},
+			}}},
+		})
+	}
+
+	schema := g.object(reflect.TypeOf(envelope{}))
+	properties := schema["properties"].(map[string]interface{})
+	properties["schema_version"] = map[string]interface{}{"const": SchemaVersion}
+	properties["artifact"] = map[string]interface{}{"enum": artifacts}
+	properties["records"] = map[string]interface{}{"type": "array"}
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "HackBrowserData"
+	schema["description"] = "Records of an artifact of browser profile in json format, field names are snake case."
+	schema["allOf"] = conditions
+	g.defs[provenanceDef] = provenance
+	schema["$defs"] = g.defs
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(schema); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]map[string]interface{}
+	// refs are types described in defs, which are referred instead of described again
+	refs map[reflect.Type]string
+}
+
+// addRecord adds the record type of artifact into defs, records of the same artifact from different
+// browsers are merged, and only fields of all of them are required.
+func (g *schemaGenerator) addRecord(artifact string, typ reflect.Type) {
+	def := g.object(typ)
+	old, ok := g.defs[artifact]
+	if !ok {
+		g.defs[artifact] = def
+		return
+	}
+	properties := old["properties"].(map[string]interface{})
+	for k, v := range def["properties"].(map[string]interface{}) {
+		properties[k] = v
+	}
+	required := make(map[string]bool)
+	for _, name := range def["required"].([]string) {
+		required[name] = true
+	}
+	merged := []string{}
+	for _, name := range old["required"].([]string) {
+		if required[name] {
+			merged = append(merged, name)
+		}
+	}
+	old["required"] = merged
+}
+
+func (g *schemaGenerator) object(typ reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, f := range recordFields(typ) {
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		name := fieldName(f)
+		properties[name] = g.schemaOf(f.Type)
+		if !strings.Contains(f.Tag.Get("json"), ",omitempty") {
+			required = append(required, name)
+		}
+	}
+	// provenance is only in records if it's enabled
+	if f, ok := typ.FieldByName("Provenance"); ok && f.Type == provenanceType {
+		properties[fieldName(f)] = g.schemaOf(f.Type)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) schemaOf(typ reflect.Type) map[string]interface{} {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if name, ok := g.refs[typ]; ok {
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+	if typ == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		// nil slice is encoded as null
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": g.schemaOf(typ.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		return g.object(typ)
+	}
+	return map[string]interface{}{}
+}
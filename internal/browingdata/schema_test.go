@@ -0,0 +1,89 @@
+package browingdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/item"
+)
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := os.ReadFile("../../schema/v" + SchemaVersion + ".schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema, published) {
+		t.Error("published schema is outdated, update it with `go run ./cmd/hack-browser-data schema > schema/v" +
+			SchemaVersion + ".schema.json` and increase SchemaVersion if fields are changed")
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	data := New("Chrome", "Default", nil)
+	source := &testLogins{{URL: "https://github.com", UserName: "user", Password: "secret"}}
+	data.sources[item.ChromiumPassword] = source
+	data.SetPaths(map[item.Item]string{item.ChromiumPassword: "/home/user/.config/google-chrome/Default/Login Data"})
+	data.collected = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	host := envelopeHost{hostname: "host", user: "user"}
+	var b bytes.Buffer
+	if err := writeJSON(&b, host.envelope(data, source, OutputOptions{ToolVersion: "1.0.0"})); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"schema_version": SchemaVersion,
+		"tool_version":   "1.0.0",
+		"hostname":       "host",
+		"user":           "user",
+		"browser":        "Chrome",
+		"profile":        "Default",
+		"artifact":       "password",
+		"source_path":    "/home/user/.config/google-chrome/Default/Login Data",
+		"collected_at":   "2022-01-02T03:04:05Z",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got %s %v, want %v", k, got[k], v)
+		}
+	}
+	if records, ok := got["records"].([]interface{}); !ok || len(records) != 1 {
+		t.Errorf("got records %v, want 1 record", got["records"])
+	}
+}
+
+func TestCSVHeaders(t *testing.T) {
+	t.Parallel()
+
+	var items []item.Item
+	for i := item.ChromiumKey; i <= item.FirefoxContainer; i++ {
+		items = append(items, i)
+	}
+	d := New("", "", items)
+	for _, source := range d.sources {
+		_, typ, ok := sourceRecords(source)
+		if !ok {
+			continue
+		}
+		// csv columns have the names of fields in the schema
+		for _, f := range recordFields(typ) {
+			if got := f.Tag.Get("csv"); got != fieldName(f) {
+				t.Errorf("got csv column %q of %s.%s, want %q", got, source.Name(), f.Name, fieldName(f))
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type engine struct {
+	name string
+	// host is matched against the hostname of url
+	host *regexp.Regexp
+	// path is the prefix of url path, empty path matches all urls of the host
+	path  string
+	param string
+}
+
+// countrySuffix matches the country domains of engine, e.g. com, de, co.uk and com.br,
+// other suffixes aren't matched, so hosts like google.evil.com aren't engines
+const countrySuffix = `\.(com|[a-z]{2}|co\.[a-z]{2}|com\.[a-z]{2})`
+
+var engines = []engine{
+	{"google", regexp.MustCompile(`(^|\.)google` + countrySuffix + `$`), "/search", "q"},
+	{"bing", regexp.MustCompile(`(^|\.)bing\.com$`), "/search", "q"},
+	{"duckduckgo", regexp.MustCompile(`(^|\.)duckduckgo\.com$`), "/", "q"},
+	{"yandex", regexp.MustCompile(`(^|\.)(yandex` + countrySuffix + `|ya\.ru)$`), "/search", "text"},
+	{"baidu", regexp.MustCompile(`(^|\.)baidu\.com$`), "/s", "wd"},
+	{"youtube", regexp.MustCompile(`(^|\.)youtube\.com$`), "/results", "search_query"},
+	{"amazon", regexp.MustCompile(`(^|\.)amazon` + countrySuffix + `$`), "/s", "k"},
+}
+
+var errInvalidEngine = errors.New("invalid search engine, format is name:host:param")
+
+// AddEngine adds a user configured search engine with format name:host:param,
+// host is a regular expression matched against the hostname, e.g. ecosia:(^|\.)ecosia\.org$:q
+func AddEngine(pattern string) error {
+	first, last := strings.Index(pattern, ":"), strings.LastIndex(pattern, ":")
+	if first <= 0 || first == last || last == len(pattern)-1 {
+		return errInvalidEngine
+	}
+	host, err := regexp.Compile(pattern[first+1 : last])
+	if err != nil {
+		return err
+	}
+	engines = append(engines, engine{
+		name:  pattern[:first],
+		host:  host,
+		param: pattern[last+1:],
+	})
+	return nil
+}
+
+// extractTerm returns the engine and decoded search term of rawURL,
+// ok is false if rawURL isn't a search of any engine.
+func extractTerm(rawURL string) (name, term string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, e := range engines {
+		if !e.host.MatchString(host) || !strings.HasPrefix(u.Path, e.path) {
+			continue
+		}
+		if term := strings.TrimSpace(u.Query().Get(e.param)); term != "" {
+			return e.name, term, true
+		}
+	}
+	return "", "", false
+}
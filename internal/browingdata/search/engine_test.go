@@ -0,0 +1,44 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestExtractTerm(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		url    string
+		engine string
+		term   string
+		ok     bool
+	}{
+		{"https://www.google.com/search?q=hack+browser+data&oq=hack", "google", "hack browser data", true},
+		{"https://www.google.co.uk/search?q=%E4%BD%A0%E5%A5%BD", "google", "你好", true},
+		{"https://www.bing.com/search?q=golang", "bing", "golang", true},
+		{"https://duckduckgo.com/?q=sqlite&t=h_", "duckduckgo", "sqlite", true},
+		{"https://yandex.ru/search/?text=firefox", "yandex", "firefox", true},
+		{"https://www.baidu.com/s?wd=chrome", "baidu", "chrome", true},
+		{"https://www.youtube.com/results?search_query=go+generics", "youtube", "go generics", true},
+		{"https://www.amazon.de/s?k=keyboard", "amazon", "keyboard", true},
+		{"https://www.google.com/maps?q=berlin", "", "", false},
+		{"https://notgoogle.com/search?q=term", "", "", false},
+		{"https://github.com/search?q=term", "", "", false},
+		{"https://www.google.com/search?q=", "", "", false},
+		{"https://www.google.com.br/search?q=termo", "google", "termo", true},
+		{"https://yandex.com.tr/search/?text=arama", "yandex", "arama", true},
+		{"https://www.amazon.co.jp/s?k=keyboard", "amazon", "keyboard", true},
+		// the engine name isn't followed by a country domain of engine
+		{"https://google.evil.com/search?q=term", "", "", false},
+		{"https://www.google.com.evil.net/search?q=term", "", "", false},
+		{"https://amazon.attacker.net/s?k=term", "", "", false},
+		{"https://www.amazon.co.uk.attacker.net/s?k=term", "", "", false},
+		{"https://yandex.evil.com/search/?text=term", "", "", false},
+	}
+	for _, tc := range testCases {
+		engine, term, ok := extractTerm(tc.url)
+		if engine != tc.engine || term != tc.term || ok != tc.ok {
+			t.Errorf("extractTerm(%s) = %s, %s, %v, want %s, %s, %v", tc.url, engine, term, ok, tc.engine, tc.term, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package search
+
+import (
+	"database/sql"
+	"os"
+	"sort"
+	"time"
+
+	"hack-browser-data/internal/item"
+	"hack-browser-data/internal/log"
+	"hack-browser-data/internal/utils/typeutil"
+
+	// import sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ChromiumSearchTerm is what the user searched for through each search engine. Terms are linked to the url
+// of search results only, Chromium doesn't record which visit a term is searched by, visits of the url
+// are the visits whose url_id is the url_id of term.
+type ChromiumSearchTerm []searchTerm
+
+type searchTerm struct {
+	KeywordID      int64     `json:"keyword_id" csv:"keyword_id"`
+	Term           string    `json:"term" csv:"term"`
+	NormalizedTerm string    `json:"normalized_term" csv:"normalized_term"`
+	URLID          int64     `json:"url_id" csv:"url_id"`
+	URL            string    `json:"url" csv:"url"`
+	Title          string    `json:"title" csv:"title"`
+	VisitCount     int       `json:"visit_count" csv:"visit_count"`
+	LastVisitTime  time.Time `json:"last_visit_time" csv:"last_visit_time"`
+	// Provenance is the row of keyword search term
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryChromiumSearchTerm = `SELECT k.rowid, k.keyword_id, k.term, k.normalized_term, u.id, u.url, COALESCE(u.title, ''), u.visit_count, u.last_visit_time
+		FROM keyword_search_terms k INNER JOIN urls u ON k.url_id = u.id`
+)
+
+func (c *ChromiumSearchTerm) Parse(masterKey []byte) error {
+	historyDB, err := sql.Open("sqlite3", item.TempChromiumSearchTerm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempChromiumSearchTerm)
+	defer historyDB.Close()
+	rows, err := historyDB.Query(queryChromiumSearchTerm)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			term, normalizedTerm, url, title       string
+			rowid, keywordID, urlID, lastVisitTime int64
+			visitCount                             int
+		)
+		if err := rows.Scan(&rowid, &keywordID, &term, &normalizedTerm, &urlID, &url, &title, &visitCount, &lastVisitTime); err != nil {
+			log.Warn(err)
+		}
+		*c = append(*c, searchTerm{
+			KeywordID:      keywordID,
+			Term:           term,
+			NormalizedTerm: normalizedTerm,
+			URLID:          urlID,
+			URL:            url,
+			Title:          title,
+			VisitCount:     visitCount,
+			LastVisitTime:  typeutil.TimeEpoch(lastVisitTime),
+			Provenance:     item.RowProvenance("keyword_search_terms", rowid),
+		})
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].LastVisitTime.After((*c)[j].LastVisitTime)
+	})
+	return nil
+}
+
+func (c *ChromiumSearchTerm) Name() string {
+	return "searchTerm"
+}
+
+func (c *ChromiumSearchTerm) Length() int {
+	return len(*c)
+}
+
+// ChromiumShortcut is the text typed into the omnibox and the url selected for it
+type ChromiumShortcut []shortcut
+
+type shortcut struct {
+	Text           string    `json:"text" csv:"text"`
+	FillIntoEdit   string    `json:"fill_into_edit" csv:"fill_into_edit"`
+	URL            string    `json:"url" csv:"url"`
+	Contents       string    `json:"contents" csv:"contents"`
+	Description    string    `json:"description" csv:"description"`
+	Keyword        string    `json:"keyword" csv:"keyword"`
+	NumberOfHits   int       `json:"number_of_hits" csv:"number_of_hits"`
+	LastAccessTime time.Time `json:"last_access_time" csv:"last_access_time"`
+	// Provenance is the row of shortcut
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryChromiumShortcut = `SELECT rowid, text, fill_into_edit, url, contents, description, keyword, number_of_hits, last_access_time FROM omni_box_shortcuts`
+)
+
+func (c *ChromiumShortcut) Parse(masterKey []byte) error {
+	shortcutDB, err := sql.Open("sqlite3", item.TempChromiumShortcut)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempChromiumShortcut)
+	defer shortcutDB.Close()
+	rows, err := shortcutDB.Query(queryChromiumShortcut)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			text, fillIntoEdit, url, contents, description, keyword string
+			numberOfHits                                            int
+			rowid, lastAccessTime                                   int64
+		)
+		if err := rows.Scan(&rowid, &text, &fillIntoEdit, &url, &contents, &description, &keyword, &numberOfHits, &lastAccessTime); err != nil {
+			log.Warn(err)
+		}
+		*c = append(*c, shortcut{
+			Text:           text,
+			FillIntoEdit:   fillIntoEdit,
+			URL:            url,
+			Contents:       contents,
+			Description:    description,
+			Keyword:        keyword,
+			NumberOfHits:   numberOfHits,
+			LastAccessTime: typeutil.TimeEpoch(lastAccessTime),
+			Provenance:     item.RowProvenance("omni_box_shortcuts", rowid),
+		})
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].LastAccessTime.After((*c)[j].LastAccessTime)
+	})
+	return nil
+}
+
+func (c *ChromiumShortcut) Name() string {
+	return "shortcut"
+}
+
+func (c *ChromiumShortcut) Length() int {
+	return len(*c)
+}
+
+// ChromiumSearch is the search terms extracted from history urls of search engines
+type ChromiumSearch []search
+
+type search struct {
+	Browser    string    `json:"browser" csv:"browser"`
+	Profile    string    `json:"profile" csv:"profile"`
+	Engine     string    `json:"engine" csv:"engine"`
+	Term       string    `json:"term" csv:"term"`
+	URL        string    `json:"url" csv:"url"`
+	SearchTime time.Time `json:"search_time" csv:"search_time"`
+	// Provenance is the row of visit which the search url is visited
+	Provenance *item.Provenance `json:"provenance,omitempty" csv:"-"`
+}
+
+const (
+	queryChromiumSearch = `SELECT visits.id, urls.url, visits.visit_time FROM visits INNER JOIN urls ON visits.url = urls.id`
+)
+
+func (c *ChromiumSearch) Parse(masterKey []byte) error {
+	historyDB, err := sql.Open("sqlite3", item.TempChromiumSearch)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempChromiumSearch)
+	defer historyDB.Close()
+	rows, err := historyDB.Query(queryChromiumSearch)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			url           string
+			id, visitTime int64
+		)
+		if err := rows.Scan(&id, &url, &visitTime); err != nil {
+			log.Warn(err)
+		}
+		if engine, term, ok := extractTerm(url); ok {
+			*c = append(*c, search{
+				Engine:     engine,
+				Term:       term,
+				URL:        url,
+				SearchTime: typeutil.TimeEpoch(visitTime),
+				Provenance: item.RowProvenance("visits", id),
+			})
+		}
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].SearchTime.After((*c)[j].SearchTime)
+	})
+	return nil
+}
+
+// SetProfile fills the browser and profile which searches are read from
+func (c *ChromiumSearch) SetProfile(browser, profile string) {
+	for i := range *c {
+		(*c)[i].Browser, (*c)[i].Profile = browser, profile
+	}
+}
+
+func (c *ChromiumSearch) Name() string {
+	return "search"
+}
+
+func (c *ChromiumSearch) Length() int {
+	return len(*c)
+}
+
+type FirefoxSearch []search
+
+const (
+	queryFirefoxSearch = `SELECT v.id, p.url, v.visit_date FROM moz_historyvisits v INNER JOIN moz_places p ON v.place_id = p.id`
+	closeJournalMode   = `PRAGMA journal_mode=off`
+)
+
+func (f *FirefoxSearch) Parse(masterKey []byte) error {
+	placesDB, err := sql.Open("sqlite3", item.TempFirefoxSearch)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(item.TempFirefoxSearch)
+	defer placesDB.Close()
+	_, err = placesDB.Exec(closeJournalMode)
+	if err != nil {
+		return err
+	}
+	rows, err := placesDB.Query(queryFirefoxSearch)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			url           string
+			id, visitDate int64
+		)
+		if err := rows.Scan(&id, &url, &visitDate); err != nil {
+			log.Warn(err)
+		}
+		if engine, term, ok := extractTerm(url); ok {
+			*f = append(*f, search{
+				Engine:     engine,
+				Term:       term,
+				URL:        url,
+				SearchTime: typeutil.TimeStamp(visitDate / 1000000),
+				Provenance: item.RowProvenance("moz_historyvisits", id),
+			})
+		}
+	}
+	sort.Slice(*f, func(i, j int) bool {
+		return (*f)[i].SearchTime.After((*f)[j].SearchTime)
+	})
+	return nil
+}
+
+// SetProfile fills the browser and profile which searches are read from
+func (f *FirefoxSearch) SetProfile(browser, profile string) {
+	for i := range *f {
+		(*f)[i].Browser, (*f)[i].Profile = browser, profile
+	}
+}
+
+func (f *FirefoxSearch) Name() string {
+	return "search"
+}
+
+func (f *FirefoxSearch) Length() int {
+	return len(*f)
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/item"
+)
+
+// createDB creates the database of filename with stmts, which is removed by the source after parsing
+func createDB(t *testing.T, filename string, stmts []string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChromiumSearchTerm(t *testing.T) {
+	t.Parallel()
+
+	createDB(t, item.TempChromiumSearchTerm, []string{
+		"CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT, visit_count INTEGER, last_visit_time INTEGER)",
+		"CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER)",
+		"CREATE TABLE keyword_search_terms (keyword_id INTEGER, url_id INTEGER, term TEXT, normalized_term TEXT)",
+		// last visit time is 2022-01-02T03:04:05Z in microseconds since 1601-01-01
+		"INSERT INTO urls VALUES (7, 'https://www.google.com/search?q=Golang', NULL, 2, 13285566245000000)",
+		"INSERT INTO visits VALUES (1, 7, 13285566200000000), (2, 7, 13285566245000000)",
+		"INSERT INTO keyword_search_terms VALUES (2, 7, 'Golang', 'golang')",
+		// the term of url which isn't in history is dropped
+		"INSERT INTO keyword_search_terms VALUES (2, 8, 'deleted', 'deleted')",
+	})
+	terms := &ChromiumSearchTerm{}
+	if err := terms.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*terms) != 1 {
+		t.Fatalf("got %d search terms, want 1", len(*terms))
+	}
+	want := searchTerm{
+		KeywordID:      2,
+		Term:           "Golang",
+		NormalizedTerm: "golang",
+		URLID:          7,
+		URL:            "https://www.google.com/search?q=Golang",
+		VisitCount:     2,
+		LastVisitTime:  time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	got := (*terms)[0]
+	if got.Provenance == nil || got.Provenance.Table != "keyword_search_terms" || got.Provenance.RowID != 1 {
+		t.Errorf("got provenance %+v, want row 1 of keyword_search_terms", got.Provenance)
+	}
+	got.Provenance = nil
+	got.LastVisitTime = got.LastVisitTime.UTC()
+	if got != want {
+		t.Errorf("got search term %+v, want %+v", got, want)
+	}
+}
+
+func TestChromiumShortcut(t *testing.T) {
+	t.Parallel()
+
+	// shortcuts are read from their own database, so urls absent from history are kept
+	createDB(t, item.TempChromiumShortcut, []string{
+		`CREATE TABLE omni_box_shortcuts (id VARCHAR PRIMARY KEY, text VARCHAR, fill_into_edit VARCHAR, url VARCHAR,
+			contents VARCHAR, contents_class VARCHAR, description VARCHAR, description_class VARCHAR, transition INTEGER,
+			type INTEGER, keyword VARCHAR, last_access_time INTEGER, number_of_hits INTEGER)`,
+		`INSERT INTO omni_box_shortcuts VALUES ('A1', 'git', 'github.com', 'https://github.com/', 'github.com', '',
+			'GitHub', '', 1, 0, '', 13285566245000000, 3)`,
+		`INSERT INTO omni_box_shortcuts VALUES ('B2', 'gola', 'golang', 'https://www.google.com/search?q=golang', 'golang', '',
+			'Google Search', '', 5, 0, 'google.com', 13285566200000000, 1)`,
+	})
+	shortcuts := &ChromiumShortcut{}
+	if err := shortcuts.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*shortcuts) != 2 {
+		t.Fatalf("got %d shortcuts, want 2", len(*shortcuts))
+	}
+	// shortcuts are sorted by last access time
+	got := (*shortcuts)[0]
+	if got.Text != "git" || got.URL != "https://github.com/" || got.Description != "GitHub" || got.NumberOfHits != 3 ||
+		!got.LastAccessTime.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("got shortcut %+v", got)
+	}
+	if got.Provenance == nil || got.Provenance.Table != "omni_box_shortcuts" || got.Provenance.RowID != 1 {
+		t.Errorf("got provenance %+v, want row 1 of omni_box_shortcuts", got.Provenance)
+	}
+	if got := (*shortcuts)[1]; got.Keyword != "google.com" || got.FillIntoEdit != "golang" {
+		t.Errorf("got shortcut %+v", got)
+	}
+}
@@ -0,0 +1,281 @@
+package browingdata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"hack-browser-data/internal/log"
+
+	// import sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteFile = "browsingdata.sqlite"
+
+func init() {
+	RegisterOutputter("sqlite", func(options OutputOptions) (Outputter, error) {
+		return &mergedOutputter{write: WriteSQLite}, nil
+	})
+}
+
+// WriteSQLite writes artifacts of all browsers into a single SQLite database. Each artifact is a table
+// with browser and profile columns, its columns of url, host and time are indexed.
+func WriteSQLite(dir string, browsers map[string]*Data) ([]string, error) {
+	tables := sqliteTables(browsers)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	var filename string
+	if dir == StdoutDir {
+		f, err := os.CreateTemp("", "*.sqlite")
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		filename = f.Name()
+		defer os.Remove(filename)
+	} else {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, err
+		}
+		filename = filepath.Join(dir, sqliteFile)
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if err := writeSQLite(filename, tables); err != nil {
+		return nil, err
+	}
+
+	if dir == StdoutDir {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if _, err := io.Copy(os.Stdout, f); err != nil {
+			return nil, err
+		}
+	}
+	log.Noticef("output to file %s success", outputPath(dir, sqliteFile))
+	return []string{sqliteFile}, nil
+}
+
+func writeSQLite(filename string, tables []*sqliteTable) error {
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := t.write(db); err != nil {
+			db.Close()
+			return fmt.Errorf("write table %s error %w", t.name, err)
+		}
+	}
+	return db.Close()
+}
+
+// sqliteTable is the table of an artifact, whose columns are the union of fields of all its sources
+type sqliteTable struct {
+	name    string
+	columns []*sqliteColumn
+	sources []tableSource
+	// hostOf is the url column which the host column is derived from, if the artifact has no host field
+	hostOf *sqliteColumn
+}
+
+type sqliteColumn struct {
+	name  string
+	typ   string
+	index bool
+}
+
+func sqliteTables(browsers map[string]*Data) []*sqliteTable {
+	var result []*sqliteTable
+	for _, rt := range recordTables(browsers) {
+		t := &sqliteTable{name: rt.name, sources: rt.sources, columns: []*sqliteColumn{
+			{name: "browser", typ: "TEXT"},
+			{name: "profile", typ: "TEXT"},
+		}}
+		for _, c := range rt.columns {
+			// the column has the type of field of the first source
+			typ, isTime := sqliteType(c.types[0])
+			t.columns = append(t.columns, &sqliteColumn{name: c.name, typ: typ, index: isTime || isURLColumn(c.name) || c.name == "host"})
+		}
+		t.addHostColumn()
+		result = append(result, t)
+	}
+	return result
+}
+
+func (t *sqliteTable) addHostColumn() {
+	if t.column("host") != nil {
+		return
+	}
+	for _, c := range t.columns {
+		if isURLColumn(c.name) {
+			t.hostOf = c
+			t.columns = append(t.columns, &sqliteColumn{name: "host", typ: "TEXT", index: true})
+			return
+		}
+	}
+}
+
+func (t *sqliteTable) column(name string) *sqliteColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *sqliteTable) write(db *sql.DB) error {
+	columns := make([]string, 0, len(t.columns))
+	names := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		columns = append(columns, fmt.Sprintf("%q %s", c.name, c.typ))
+		names = append(names, fmt.Sprintf("%q", c.name))
+	}
+	// rows are keyed by the implicit rowid, id is a field of records such as bookmarks and visits
+	stmts := []string{fmt.Sprintf("CREATE TABLE %q (%s)", t.name, strings.Join(columns, ", "))}
+	stmts = append(stmts, fmt.Sprintf("CREATE INDEX %q ON %q (browser, profile)", "idx_"+t.name+"_browser", t.name))
+	for _, c := range t.columns {
+		if c.index {
+			stmts = append(stmts, fmt.Sprintf("CREATE INDEX %q ON %q (%q)", "idx_"+t.name+"_"+c.name, t.name, c.name))
+		}
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %q (%s) VALUES (%s)",
+		t.name, strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	values := make([]interface{}, len(t.columns))
+	for _, s := range t.sources {
+		for i := 0; i < s.records.Len(); i++ {
+			rec := record(s.records, i)
+			for j, c := range t.columns {
+				values[j] = nil
+				switch {
+				case c.name == "browser":
+					values[j] = s.data.browser
+				case c.name == "profile":
+					values[j] = s.data.profile
+				case c.name == "host" && t.hostOf != nil:
+					if index, ok := s.fields[t.hostOf.name]; ok {
+						values[j] = hostOf(rec.FieldByIndex(index))
+					}
+				default:
+					if index, ok := s.fields[c.name]; ok {
+						values[j] = sqliteValue(rec.FieldByIndex(index))
+					}
+				}
+			}
+			if _, err := insert.Exec(values...); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// sqliteType returns the column type of field type, and whether it's a time column
+func sqliteType(typ reflect.Type) (string, bool) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return "TEXT", true
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER", false
+	case reflect.Float32, reflect.Float64:
+		return "REAL", false
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "BLOB", false
+		}
+	case reflect.String:
+		return "TEXT", false
+	}
+	// other types are stored as json
+	return "TEXT", false
+}
+
+// sqliteValue converts field to the value of its column, time is stored as RFC3339 in UTC
+func sqliteValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+func isURLColumn(name string) bool {
+	return name == "url" || strings.HasSuffix(name, "_url")
+}
+
+func hostOf(v reflect.Value) interface{} {
+	if v.Kind() != reflect.String {
+		return nil
+	}
+	u, err := url.Parse(v.String())
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,98 @@
+package browingdata
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hack-browser-data/internal/browingdata/bookmark"
+	"hack-browser-data/internal/browingdata/history"
+	"hack-browser-data/internal/item"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	t.Parallel()
+
+	chrome := New("Chrome", "Default", nil)
+	chrome.sources[item.ChromiumPassword] = &testLogins{
+		{URL: "https://github.com/login", UserName: "user", Password: "secret", CreateDate: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	firefox := New("Firefox", "default-release", nil)
+	firefox.sources[item.FirefoxPassword] = &testLogins{{URL: "https://example.com", UserName: "admin"}}
+	firefox.sources[item.ChromiumCreditCard] = &testCards{}
+	tables := sqliteTables(map[string]*Data{"chrome_default": chrome, "firefox_default": firefox})
+	if len(tables) != 1 {
+		t.Fatalf("got %d tables, want only password table", len(tables))
+	}
+
+	filename := filepath.Join(t.TempDir(), sqliteFile)
+	if err := writeSQLite(filename, tables); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var (
+		browser, profile, userName, host string
+		createDate                       sql.NullString
+	)
+	row := db.QueryRow(`SELECT browser, profile, user_name, host, create_date FROM password WHERE host = 'github.com'`)
+	if err := row.Scan(&browser, &profile, &userName, &host, &createDate); err != nil {
+		t.Fatal(err)
+	}
+	if browser != "Chrome" || profile != "Default" || userName != "user" || createDate.String != "2022-01-02T03:04:05Z" {
+		t.Errorf("got %s %s %s %s, want Chrome Default user 2022-01-02T03:04:05Z", browser, profile, userName, createDate.String)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'password'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	// indexes of browser, url, create_date and host
+	if count != 4 {
+		t.Errorf("got %d indexes, want 4", count)
+	}
+}
+
+func TestWriteSQLiteRecordID(t *testing.T) {
+	t.Parallel()
+
+	chrome := New("Chrome", "Default", nil)
+	bookmarks, visits := &bookmark.ChromiumBookmark{}, &history.ChromiumVisit{}
+	testRecords(t, bookmarks, `[{"id":7,"name":"GitHub","type":"url","url":"https://github.com","date_added":"2022-01-02T03:04:05Z"}]`)
+	testRecords(t, visits, `[{"id":42,"url":"https://github.com","visit_time":"2022-01-02T03:04:05Z","transition":"typed","visit_duration_us":1500000}]`)
+	chrome.sources[item.ChromiumBookmark] = bookmarks
+	chrome.sources[item.ChromiumVisit] = visits
+	tables := sqliteTables(map[string]*Data{"chrome_default": chrome})
+	if len(tables) != 2 {
+		t.Fatalf("got %d tables, want bookmark and visit tables", len(tables))
+	}
+
+	filename := filepath.Join(t.TempDir(), sqliteFile)
+	if err := writeSQLite(filename, tables); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var (
+		id, duration int64
+		name         string
+	)
+	if err := db.QueryRow(`SELECT id, name FROM bookmark`).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 || name != "GitHub" {
+		t.Errorf("got bookmark %d %s, want 7 GitHub", id, name)
+	}
+	if err := db.QueryRow(`SELECT id, visit_duration_us FROM visit WHERE host = 'github.com'`).Scan(&id, &duration); err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 || duration != 1500000 {
+		t.Errorf("got visit %d %d, want 42 1500000", id, duration)
+	}
+}
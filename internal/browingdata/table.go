@@ -0,0 +1,92 @@
+package browingdata
+
+import (
+	"reflect"
+	"sort"
+
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+// recordTable is the table of an artifact in tabular formats, e.g. sqlite and parquet, whose columns
+// are the union of fields of records of all its sources. Browser and profile columns aren't included,
+// formats add them before the columns of fields.
+type recordTable struct {
+	name    string
+	columns []*tableColumn
+	sources []tableSource
+}
+
+// tableColumn is a column of fields, types are the field types of sources which have the field
+type tableColumn struct {
+	name  string
+	types []reflect.Type
+}
+
+// tableSource is a source of table and the struct fields of its records mapped to the columns of table
+type tableSource struct {
+	data    *Data
+	records reflect.Value
+	fields  map[string][]int
+}
+
+// recordTables returns the tables of artifacts of all browsers sorted by name, sources of each table
+// are in the order of browser names
+func recordTables(browsers map[string]*Data) []*recordTable {
+	tables := make(map[string]*recordTable)
+	names := typeutil.Keys(browsers)
+	sort.Strings(names)
+	for _, name := range names {
+		d := browsers[name]
+		for _, source := range d.sources {
+			if source.Length() == 0 {
+				continue
+			}
+			records, elem, ok := sourceRecords(source)
+			if !ok {
+				continue
+			}
+			tableName := typeutil.SnakeCase(source.Name())
+			t, ok := tables[tableName]
+			if !ok {
+				t = &recordTable{name: tableName}
+				tables[tableName] = t
+			}
+			t.sources = append(t.sources, tableSource{data: d, records: records, fields: t.addColumns(tableFields(records, elem))})
+		}
+	}
+
+	result := make([]*recordTable, 0, len(tables))
+	for _, t := range tables {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// addColumns adds fields of records as columns, and returns the fields of columns
+func (t *recordTable) addColumns(structFields []reflect.StructField) map[string][]int {
+	fields := make(map[string][]int)
+	for _, f := range structFields {
+		name := fieldName(f)
+		if name == "browser" || name == "profile" {
+			// records tagged with browser and profile themselves are same as the columns
+			continue
+		}
+		fields[name] = f.Index
+		if c := t.column(name); c != nil {
+			c.types = append(c.types, f.Type)
+			continue
+		}
+		t.columns = append(t.columns, &tableColumn{name: name, types: []reflect.Type{f.Type}})
+	}
+	return fields
+}
+
+func (t *recordTable) column(name string) *tableColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
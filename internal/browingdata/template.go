@@ -0,0 +1,163 @@
+package browingdata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"hack-browser-data/internal/utils/typeutil"
+)
+
+func init() {
+	RegisterOutputter("template", func(options OutputOptions) (Outputter, error) {
+		if options.Template == "" {
+			return nil, errors.New("template file is required")
+		}
+		t, err := template.New(filepath.Base(options.Template)).Funcs(templateFuncs).ParseFiles(options.Template)
+		if err != nil {
+			return nil, err
+		}
+		return &fileOutputter{ext: templateExt(options.Template), write: func(w io.Writer, data *Data, source Source) error {
+			return writeTemplate(w, t, data, source)
+		}, supports: func(source Source) bool {
+			_, _, ok := sourceRecords(source)
+			return ok
+		}}, nil
+	})
+}
+
+// templateData is the data of template, which is an artifact of browser profile
+type templateData struct {
+	Browser string
+	Profile string
+	Item    string
+	// Fields are names of the exported fields of records
+	Fields []string
+	// Records is the slice of records, whose fields are accessed by name, e.g. {{range .Records}}{{.URL}}{{end}}
+	Records interface{}
+}
+
+var templateFuncs = template.FuncMap{
+	"formatTime": templateFormatTime,
+	"unixTime":   templateUnixTime,
+	"csv":        templateCSV,
+	"json":       templateJSON,
+	"mask":       templateMask,
+	"field":      templateField,
+	"snake":      typeutil.SnakeCase,
+}
+
+// templateExt returns the file extension of output, which is the extension before .tmpl of template,
+// e.g. csv of passwords.csv.tmpl, and txt if there is none.
+func templateExt(filename string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), ".tmpl")
+	if ext := filepath.Ext(name); len(ext) > 1 && name != filepath.Base(filename) {
+		return ext[1:]
+	}
+	return "txt"
+}
+
+func writeTemplate(w io.Writer, t *template.Template, data *Data, source Source) error {
+	records, typ, _ := sourceRecords(source)
+	d := templateData{
+		Browser: data.browser,
+		Profile: data.profile,
+		Item:    source.Name(),
+		Records: records.Interface(),
+	}
+	for _, f := range recordFields(typ) {
+		d.Fields = append(d.Fields, f.Name)
+	}
+	return t.Execute(w, d)
+}
+
+// templateFormatTime formats time with layout of Go, zero time is formatted as empty string
+func templateFormatTime(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// templateUnixTime returns the seconds since epoch of time, zero time is 0
+func templateUnixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// templateCSV returns values as a line of csv without the line break, each value is quoted if needed
+func templateCSV(values ...interface{}) (string, error) {
+	row := make([]string, 0, len(values))
+	for _, v := range values {
+		row = append(row, templateString(v))
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(row); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), w.Error()
+}
+
+// templateJSON returns value as json, e.g. a quoted and escaped string
+func templateJSON(v interface{}) (string, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// templateMask masks all but the last 4 characters of s, which are also masked if s is shorter than 8 characters
+func templateMask(s string) string {
+	r := []rune(s)
+	keep := 0
+	if len(r) >= 8 {
+		keep = 4
+	}
+	return strings.Repeat("*", len(r)-keep) + string(r[len(r)-keep:])
+}
+
+// templateField returns the field of record by name, it's used with .Fields to write all fields of records
+func templateField(rec interface{}, name string) (interface{}, error) {
+	v := reflect.Indirect(reflect.ValueOf(rec))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a record", rec)
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return nil, fmt.Errorf("record has no field %s", name)
+	}
+	return f.Interface(), nil
+}
+
+// templateString formats value in csv, time is formatted as RFC3339 and zero time is empty
+func templateString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case []byte:
+		return fmt.Sprintf("%x", v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
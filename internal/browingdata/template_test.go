@@ -0,0 +1,47 @@
+package browingdata
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"hack-browser-data/internal/browingdata/password"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `{{csv "url" "username" "password" "created"}}
+{{range .Records}}{{csv .LoginURL .UserName (mask .Password) (formatTime "2006-01-02" .CreateDate)}}
+{{end}}{{range $i, $r := .Records}}{{range $.Fields}}{{snake .}}={{json (field $r .)}} {{end}}{{end}}`
+	tmpl := template.Must(template.New("test").Funcs(templateFuncs).Parse(text))
+	data := New("Chrome", "Default", nil)
+	source := &password.ChromiumPassword{}
+	testRecords(t, source, `[{"login_url":"https://github.com","user_name":"user,\"name\"","password":"password123","create_date":"2022-01-02T03:04:05Z"}]`)
+	var b strings.Builder
+	if err := writeTemplate(&b, tmpl, data, source); err != nil {
+		t.Fatal(err)
+	}
+	want := `url,username,password,created
+https://github.com,"user,""name""",*******d123,2022-01-02
+user_name="user,\"name\"" password="password123" login_url="https://github.com" create_date="2022-01-02T03:04:05Z" `
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestTemplateExt(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"passwords.csv.tmpl": "csv",
+		"dir/report.md.tmpl": "md",
+		"report.tmpl":        "txt",
+		"report.html":        "txt",
+	}
+	for filename, want := range testCases {
+		if got := templateExt(filename); got != want {
+			t.Errorf("templateExt(%s) = %s, want %s", filename, got, want)
+		}
+	}
+}
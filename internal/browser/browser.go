@@ -8,5 +8,5 @@ type Browser interface {
 	// Name is browser's name
 	Name() string
 	// BrowsingData returns all browsing data in the browser.
-	BrowsingData() (*browingdata.Data, error)
+	BrowsingData(options browingdata.Options) (*browingdata.Data, error)
 }
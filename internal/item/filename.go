@@ -2,15 +2,21 @@ package item
 
 // item's default filename
 const (
-	fileChromiumKey          = "Local State"
-	fileChromiumCredit       = "Web Data"
-	fileChromiumPassword     = "Login Data"
-	fileChromiumHistory      = "History"
-	fileChromiumDownload     = "History"
-	fileChromiumCookie       = "Cookies"
-	fileChromiumBookmark     = "Bookmarks"
-	fileChromiumLocalStorage = "Local Storage/leveldb"
-	fileChromiumExtension    = "Extensions"
+	fileChromiumKey             = "Local State"
+	fileChromiumCredit          = "Web Data"
+	fileChromiumPassword        = "Login Data"
+	fileChromiumHistory         = "History"
+	fileChromiumVisit           = "History"
+	fileChromiumSearchTerm      = "History"
+	fileChromiumShortcut        = "Shortcuts"
+	fileChromiumSearch          = "History"
+	fileChromiumDownload        = "History"
+	fileChromiumCookie          = "Cookies"
+	fileChromiumExtensionCookie = "Extension Cookies"
+	fileChromiumBookmark        = "Bookmarks"
+	fileChromiumBookmarkBackup  = "Bookmarks.bak"
+	fileChromiumLocalStorage    = "Local Storage/leveldb"
+	fileChromiumExtension       = "Extensions"
 
 	fileYandexPassword = "Ya Passman Data"
 	fileYandexCredit   = "Ya Credit Cards"
@@ -21,6 +27,7 @@ const (
 	fileFirefoxData         = "places.sqlite"
 	fileFirefoxLocalStorage = "webappsstore.sqlite"
 	fileFirefoxExtension    = "extensions.json"
+	fileFirefoxContainer    = "containers.json"
 )
 
 const (
@@ -29,15 +36,21 @@ const (
 )
 
 const (
-	TempChromiumKey          = "chromiumKey"
-	TempChromiumPassword     = "password"
-	TempChromiumCookie       = "cookie"
-	TempChromiumBookmark     = "bookmark"
-	TempChromiumHistory      = "history"
-	TempChromiumDownload     = "download"
-	TempChromiumCreditCard   = "creditCard"
-	TempChromiumLocalStorage = "localStorage"
-	TempChromiumExtension    = "extension"
+	TempChromiumKey             = "chromiumKey"
+	TempChromiumPassword        = "password"
+	TempChromiumCookie          = "cookie"
+	TempChromiumExtensionCookie = "extensionCookie"
+	TempChromiumBookmark        = "bookmark"
+	TempChromiumBookmarkChange  = "bookmarkChange"
+	TempChromiumHistory         = "history"
+	TempChromiumVisit           = "visit"
+	TempChromiumSearchTerm      = "searchTerm"
+	TempChromiumShortcut        = "shortcut"
+	TempChromiumSearch          = "search"
+	TempChromiumDownload        = "download"
+	TempChromiumCreditCard      = "creditCard"
+	TempChromiumLocalStorage    = "localStorage"
+	TempChromiumExtension       = "extension"
 
 	TempYandexPassword   = "yandexPassword"
 	TempYandexCreditCard = "yandexCreditCard"
@@ -47,8 +60,13 @@ const (
 	TempFirefoxCookie       = "firefoxCookie"
 	TempFirefoxBookmark     = "firefoxBookmark"
 	TempFirefoxHistory      = "firefoxHistory"
+	TempFirefoxVisit        = "firefoxVisit"
+	TempFirefoxInputHistory = "firefoxInputHistory"
+	TempFirefoxOrigin       = "firefoxOrigin"
+	TempFirefoxSearch       = "firefoxSearch"
 	TempFirefoxDownload     = "firefoxDownload"
 	TempFirefoxLocalStorage = "firefoxLocalStorage"
 	TempFirefoxCreditCard   = ""
 	TempFirefoxExtension    = "firefoxExtension"
+	TempFirefoxContainer    = "firefoxContainer"
 )
@@ -25,6 +25,20 @@ const (
 	FirefoxCreditCard
 	FirefoxLocalStorage
 	FirefoxExtension
+
+	// items below are appended to keep the values of items above
+	ChromiumExtensionCookie
+	ChromiumBookmarkChange
+	ChromiumVisit
+	ChromiumSearchTerm
+	ChromiumShortcut
+	ChromiumSearch
+
+	FirefoxVisit
+	FirefoxInputHistory
+	FirefoxOrigin
+	FirefoxSearch
+	FirefoxContainer
 )
 
 func (i Item) FileName() string {
@@ -35,8 +49,12 @@ func (i Item) FileName() string {
 		return fileChromiumPassword
 	case ChromiumCookie:
 		return fileChromiumCookie
+	case ChromiumExtensionCookie:
+		return fileChromiumExtensionCookie
 	case ChromiumBookmark:
 		return fileChromiumBookmark
+	case ChromiumBookmarkChange:
+		return fileChromiumBookmarkBackup
 	case ChromiumDownload:
 		return fileChromiumDownload
 	case ChromiumLocalStorage:
@@ -47,6 +65,14 @@ func (i Item) FileName() string {
 		return fileChromiumExtension
 	case ChromiumHistory:
 		return fileChromiumHistory
+	case ChromiumVisit:
+		return fileChromiumVisit
+	case ChromiumSearchTerm:
+		return fileChromiumSearchTerm
+	case ChromiumShortcut:
+		return fileChromiumShortcut
+	case ChromiumSearch:
+		return fileChromiumSearch
 	case YandexPassword:
 		return fileYandexPassword
 	case YandexCreditCard:
@@ -65,8 +91,18 @@ func (i Item) FileName() string {
 		return fileFirefoxLocalStorage
 	case FirefoxHistory:
 		return fileFirefoxData
+	case FirefoxVisit:
+		return fileFirefoxData
+	case FirefoxInputHistory:
+		return fileFirefoxData
+	case FirefoxOrigin:
+		return fileFirefoxData
+	case FirefoxSearch:
+		return fileFirefoxData
 	case FirefoxExtension:
 		return fileFirefoxExtension
+	case FirefoxContainer:
+		return fileFirefoxContainer
 	case FirefoxCreditCard:
 		return UnsupportedItem
 	default:
@@ -82,8 +118,12 @@ func (i Item) String() string {
 		return TempChromiumPassword
 	case ChromiumCookie:
 		return TempChromiumCookie
+	case ChromiumExtensionCookie:
+		return TempChromiumExtensionCookie
 	case ChromiumBookmark:
 		return TempChromiumBookmark
+	case ChromiumBookmarkChange:
+		return TempChromiumBookmarkChange
 	case ChromiumDownload:
 		return TempChromiumDownload
 	case ChromiumLocalStorage:
@@ -94,6 +134,14 @@ func (i Item) String() string {
 		return TempChromiumExtension
 	case ChromiumHistory:
 		return TempChromiumHistory
+	case ChromiumVisit:
+		return TempChromiumVisit
+	case ChromiumSearchTerm:
+		return TempChromiumSearchTerm
+	case ChromiumShortcut:
+		return TempChromiumShortcut
+	case ChromiumSearch:
+		return TempChromiumSearch
 	case YandexPassword:
 		return TempYandexPassword
 	case YandexCreditCard:
@@ -110,12 +158,22 @@ func (i Item) String() string {
 		return TempFirefoxDownload
 	case FirefoxHistory:
 		return TempFirefoxHistory
+	case FirefoxVisit:
+		return TempFirefoxVisit
+	case FirefoxInputHistory:
+		return TempFirefoxInputHistory
+	case FirefoxOrigin:
+		return TempFirefoxOrigin
+	case FirefoxSearch:
+		return TempFirefoxSearch
 	case FirefoxLocalStorage:
 		return TempFirefoxLocalStorage
 	case FirefoxCreditCard:
 		return UnsupportedItem
 	case FirefoxExtension:
 		return TempFirefoxExtension
+	case FirefoxContainer:
+		return TempFirefoxContainer
 	default:
 		return UnknownItem
 	}
@@ -125,8 +183,13 @@ var DefaultFirefox = []Item{
 	FirefoxKey4,
 	FirefoxPassword,
 	FirefoxCookie,
+	FirefoxContainer,
 	FirefoxBookmark,
 	FirefoxHistory,
+	FirefoxVisit,
+	FirefoxInputHistory,
+	FirefoxOrigin,
+	FirefoxSearch,
 	FirefoxDownload,
 	FirefoxCreditCard,
 	FirefoxLocalStorage,
@@ -136,8 +199,14 @@ var DefaultFirefox = []Item{
 var DefaultYandex = []Item{
 	ChromiumKey,
 	ChromiumCookie,
+	ChromiumExtensionCookie,
 	ChromiumBookmark,
+	ChromiumBookmarkChange,
 	ChromiumHistory,
+	ChromiumVisit,
+	ChromiumSearchTerm,
+	ChromiumShortcut,
+	ChromiumSearch,
 	ChromiumDownload,
 	ChromiumExtension,
 	YandexPassword,
@@ -149,8 +218,14 @@ var DefaultChromium = []Item{
 	ChromiumKey,
 	ChromiumPassword,
 	ChromiumCookie,
+	ChromiumExtensionCookie,
 	ChromiumBookmark,
+	ChromiumBookmarkChange,
 	ChromiumHistory,
+	ChromiumVisit,
+	ChromiumSearchTerm,
+	ChromiumShortcut,
+	ChromiumSearch,
 	ChromiumDownload,
 	ChromiumCreditCard,
 	ChromiumLocalStorage,
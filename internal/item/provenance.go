@@ -0,0 +1,55 @@
+package item
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Provenance is where a record is read from, it traces the record back to the row of evidence.
+// Parsers fill the location of record in its file, and the rest is filled after parsing.
+type Provenance struct {
+	// SourcePath is the absolute path of file or folder in browser profile
+	SourcePath string `json:"source_path"`
+	Artifact   string `json:"artifact"`
+	// Table and RowID are the row of record in SQLite database
+	Table string `json:"table,omitempty"`
+	RowID int64  `json:"rowid,omitempty"`
+	// Key is the key of record in LevelDB
+	Key string `json:"key,omitempty"`
+	// Pointer is the JSON pointer of record in JSON file, e.g. /roots/bookmark_bar/children/0
+	Pointer string `json:"pointer,omitempty"`
+	// File is the file in the snapshot folder which record is read from, it's joined to SourcePath after parsing
+	File string `json:"-"`
+	// SHA256 is the hash of the snapshot of source file which record is read from
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// RowProvenance returns the provenance of row in table of SQLite database
+func RowProvenance(table string, rowid int64) *Provenance {
+	return &Provenance{Table: table, RowID: rowid}
+}
+
+// KeyProvenance returns the provenance of key in LevelDB
+func KeyProvenance(key string) *Provenance {
+	return &Provenance{Key: key}
+}
+
+// PointerProvenance returns the provenance of JSON value at pointer
+func PointerProvenance(pointer string) *Provenance {
+	return &Provenance{Pointer: pointer}
+}
+
+// JSONPointer returns the JSON pointer of tokens, which are object keys or array indexes, e.g. /roots/bookmark_bar
+func JSONPointer(tokens ...interface{}) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		switch t := t.(type) {
+		case int:
+			b.WriteString(strconv.Itoa(t))
+		case string:
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,21 @@
+package item
+
+import "testing"
+
+func TestJSONPointer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		tokens []interface{}
+		want   string
+	}{
+		{[]interface{}{"roots", "bookmark_bar", "children", 0}, "/roots/bookmark_bar/children/0"},
+		{[]interface{}{"a/b", "m~n"}, "/a~1b/m~0n"},
+		{nil, ""},
+	}
+	for _, tc := range testCases {
+		if got := JSONPointer(tc.tokens...); got != tc.want {
+			t.Errorf("JSONPointer(%v) = %s, want %s", tc.tokens, got, tc.want)
+		}
+	}
+}
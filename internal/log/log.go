@@ -1,13 +1,17 @@
 package log
 
 import (
+	"io"
 	"os"
 
 	"github.com/gookit/color"
 	"github.com/gookit/slog"
 )
 
-var std = &slog.SugaredLogger{}
+var (
+	std           = &slog.SugaredLogger{}
+	out io.Writer = os.Stdout
+)
 
 func Init(l string) {
 	if l == "debug" {
@@ -17,11 +21,16 @@ func Init(l string) {
 	}
 }
 
+// SetOutput sets the output of logger, default is stdout. It must be called before Init.
+func SetOutput(w io.Writer) {
+	out = w
+}
+
 const template = "[{{level}}] [{{caller}}] {{message}} {{data}} {{extra}}\n"
 
 // NewStdLogger instance
 func newStdLogger(level slog.Level) *slog.SugaredLogger {
-	return slog.NewSugaredLogger(os.Stdout, level).Configure(func(sl *slog.SugaredLogger) {
+	return slog.NewSugaredLogger(out, level).Configure(func(sl *slog.SugaredLogger) {
 		sl.SetName("stdLogger")
 		sl.ReportCaller = true
 		sl.CallerSkip = 3
@@ -2,6 +2,7 @@ package chromium
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -14,6 +15,8 @@ import (
 
 type chromium struct {
 	name        string
+	browser     string
+	profile     string
 	storage     string
 	profilePath string
 	masterKey   []byte
@@ -36,10 +39,14 @@ func New(name, storage, profilePath string, items []item.Item) ([]browser.Browse
 	chromiumList := make([]browser.Browser, 0, len(multiItemPaths))
 	for user, itemPaths := range multiItemPaths {
 		chromiumList = append(chromiumList, &chromium{
-			name:      fileutil.BrowserName(name, user),
-			items:     typeutil.Keys(itemPaths),
-			itemPaths: itemPaths,
-			storage:   storage,
+			name:    fileutil.BrowserName(name, user),
+			browser: name,
+			profile: user,
+			// profiles are the folders in user data dir, which is the parent of profile path
+			profilePath: filepath.Join(fileutil.ParentDir(profilePath), user),
+			items:       typeutil.Keys(itemPaths),
+			itemPaths:   itemPaths,
+			storage:     storage,
 		})
 	}
 	return chromiumList, nil
@@ -49,12 +56,17 @@ func (c *chromium) Name() string {
 	return c.name
 }
 
-func (c *chromium) BrowsingData() (*browingdata.Data, error) {
-	b := browingdata.New(c.items)
+func (c *chromium) BrowsingData(options browingdata.Options) (*browingdata.Data, error) {
+	b := browingdata.New(c.browser, c.profile, c.items)
+	b.SetProfilePath(c.profilePath)
+	b.SetPaths(c.itemPaths)
+	b.SetProvenance(options.Provenance)
 
-	if err := c.copyItemToLocal(); err != nil {
+	evidence, err := c.copyItemToLocal(fileutil.CopyOptions{Evidence: options.Evidence})
+	if err != nil {
 		return nil, err
 	}
+	b.SetEvidence(evidence)
 
 	masterKey, err := c.GetMasterKey()
 	if err != nil {
@@ -68,26 +80,61 @@ func (c *chromium) BrowsingData() (*browingdata.Data, error) {
 	return b, nil
 }
 
-func (c *chromium) copyItemToLocal() error {
+// copyItemToLocal copies items to local, and returns the evidence of copied files of each item if it's enabled
+func (c *chromium) copyItemToLocal(options fileutil.CopyOptions) (map[item.Item][]*fileutil.Evidence, error) {
+	evidence := make(map[item.Item][]*fileutil.Evidence)
 	for i, path := range c.itemPaths {
 		filename := i.String()
-		var err error
+		var (
+			files []*fileutil.Evidence
+			err   error
+		)
 		switch {
 		case fileutil.FolderExists(path):
 			if i == item.ChromiumLocalStorage {
-				err = fileutil.CopyDir(path, filename, "lock")
+				files, err = fileutil.CopyDir(path, filename, "lock", options)
 			}
 			if i == item.ChromiumExtension {
-				err = fileutil.CopyDirHasSuffix(path, filename, "manifest.json")
+				files, err = fileutil.CopyDirHasSuffix(path, filename, "manifest.json", options)
 			}
+		case i == item.ChromiumBookmarkChange:
+			files, err = copyBookmarkBackup(path, filename, options)
 		default:
-			err = fileutil.CopyFile(path, filename)
+			var e *fileutil.Evidence
+			if e, err = fileutil.CopyFile(path, filename, options); e != nil {
+				files = []*fileutil.Evidence{e}
+			}
+		}
+		if files != nil {
+			evidence[i] = files
 		}
 		if err != nil {
-			return err
+			return evidence, err
+		}
+	}
+	return evidence, nil
+}
+
+// copyBookmarkBackup copies Bookmarks.bak and Bookmarks next to it into dst,
+// bookmark changes are found by comparing them.
+func copyBookmarkBackup(backup, dst string, options fileutil.CopyOptions) ([]*fileutil.Evidence, error) {
+	if err := os.MkdirAll(dst, 0o700); err != nil {
+		return nil, err
+	}
+	var evidence []*fileutil.Evidence
+	for _, f := range []struct{ src, dst string }{
+		{filepath.Join(filepath.Dir(backup), item.ChromiumBookmark.FileName()), item.ChromiumBookmark.FileName()},
+		{backup, item.ChromiumBookmarkChange.FileName()},
+	} {
+		e, err := fileutil.CopyFile(f.src, filepath.Join(dst, f.dst), options)
+		if err != nil {
+			return evidence, err
+		}
+		if e != nil {
+			evidence = append(evidence, e)
 		}
 	}
-	return nil
+	return evidence, nil
 }
 
 func (c *chromium) getMultiItemPath(profilePath string, items []item.Item) (map[string]map[item.Item]string, error) {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 
 	"hack-browser-data/internal/browingdata"
@@ -15,6 +16,8 @@ import (
 
 type firefox struct {
 	name        string
+	browser     string
+	profile     string
 	storage     string
 	profilePath string
 	masterKey   []byte
@@ -38,11 +41,14 @@ func New(name, storage, profilePath string, items []item.Item) ([]browser.Browse
 	}
 
 	firefoxList := make([]browser.Browser, 0, len(multiItemPaths))
-	for name, itemPaths := range multiItemPaths {
+	for profile, itemPaths := range multiItemPaths {
 		firefoxList = append(firefoxList, &firefox{
-			name:      fmt.Sprintf("firefox-%s", name),
-			items:     typeutil.Keys(itemPaths),
-			itemPaths: itemPaths,
+			name:        fmt.Sprintf("firefox-%s", profile),
+			browser:     name,
+			profile:     profile,
+			profilePath: profileFolder(itemPaths),
+			items:       typeutil.Keys(itemPaths),
+			itemPaths:   itemPaths,
 		})
 	}
 	return firefoxList, nil
@@ -54,14 +60,28 @@ func (f *firefox) getMultiItemPath(profilePath string, items []item.Item) (map[s
 	return multiItemPaths, err
 }
 
-func (f *firefox) copyItemToLocal() error {
+// profileFolder returns the folder of profile, which is the parent folder of its items
+func profileFolder(itemPaths map[item.Item]string) string {
+	for _, p := range itemPaths {
+		return fileutil.ParentDir(p)
+	}
+	return ""
+}
+
+// copyItemToLocal copies items to local, and returns the evidence of copied files of each item if it's enabled
+func (f *firefox) copyItemToLocal(options fileutil.CopyOptions) (map[item.Item][]*fileutil.Evidence, error) {
+	evidence := make(map[item.Item][]*fileutil.Evidence)
 	for i, path := range f.itemPaths {
 		filename := i.String()
-		if err := fileutil.CopyFile(path, filename); err != nil {
-			return err
+		e, err := fileutil.CopyFile(path, filename, options)
+		if err != nil {
+			return evidence, err
+		}
+		if e != nil {
+			evidence[i] = []*fileutil.Evidence{e}
 		}
 	}
-	return nil
+	return evidence, nil
 }
 
 func firefoxWalkFunc(items []item.Item, multiItemPaths map[string]map[item.Item]string) filepath.WalkFunc {
@@ -88,12 +108,19 @@ func (f *firefox) Name() string {
 	return f.name
 }
 
-func (f *firefox) BrowsingData() (*browingdata.Data, error) {
-	b := browingdata.New(f.items)
+func (f *firefox) BrowsingData(options browingdata.Options) (*browingdata.Data, error) {
+	b := browingdata.New(f.browser, f.profile, f.items)
+	b.SetProfilePath(f.profilePath)
+	b.SetPaths(f.itemPaths)
+	b.SetProvenance(options.Provenance)
 
-	if err := f.copyItemToLocal(); err != nil {
+	evidence, err := f.copyItemToLocal(fileutil.CopyOptions{Evidence: options.Evidence})
+	if err != nil {
 		return nil, err
 	}
+	b.SetEvidence(evidence)
+	// containers are read by cookies but have no source of their own, which removes the copy after parsing
+	defer os.Remove(item.FirefoxContainer.String())
 
 	masterKey, err := f.GetMasterKey()
 	if err != nil {
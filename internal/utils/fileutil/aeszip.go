@@ -0,0 +1,249 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// WinZip AES encryption, see https://www.winzip.com/en/support/aes-encryption/
+const (
+	aesMethod       = 99
+	aesExtraID      = 0x9901
+	aesVersion2     = 2
+	aesStrength256  = 3
+	aesKeyLen       = 32
+	aesSaltLen      = 16
+	aesVerifierLen  = 2
+	aesMACLen       = 10
+	aesPBKDF2Rounds = 1000
+
+	zipEncrypted      = 0x1
+	zipDataDescriptor = 0x8
+)
+
+var (
+	errZipPassword = errors.New("incorrect zip password")
+	errZipMAC      = errors.New("zip authentication code mismatch")
+)
+
+// aesFileWriter writes a deflated file of zip encrypted with WinZip AE-2 AES-256,
+// sizes of file are written in data descriptor after the content is streamed.
+type aesFileWriter struct {
+	fh     *zip.FileHeader
+	raw    io.Writer
+	comp   *flate.Writer
+	cipher *aesCipherWriter
+	size   uint64
+}
+
+// createAESFile adds a file of fh to zw, whose content written to the returned writer is encrypted with password
+func createAESFile(zw *zip.Writer, fh *zip.FileHeader, password string) (*aesFileWriter, error) {
+	salt := make([]byte, aesSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encKey, macKey, verifier := aesKeys(password, salt, aesKeyLen)
+	stream, err := newAESCTR(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], aesExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], aesVersion2)
+	copy(extra[6:], "AE")
+	extra[8] = aesStrength256
+	binary.LittleEndian.PutUint16(extra[9:], zip.Deflate)
+
+	fh.Method = aesMethod
+	fh.Flags |= zipEncrypted | zipDataDescriptor
+	fh.Extra = append(fh.Extra, extra...)
+	// AE-2 doesn't store crc32 of plain content, the authentication code is used instead
+	fh.CRC32 = 0
+
+	raw, err := zw.CreateRaw(fh)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := raw.Write(append(salt, verifier...)); err != nil {
+		return nil, err
+	}
+	w := &aesFileWriter{
+		fh:     fh,
+		raw:    raw,
+		cipher: &aesCipherWriter{w: raw, stream: stream, mac: hmac.New(sha1.New, macKey)},
+	}
+	if w.comp, err = flate.NewWriter(w.cipher, flate.DefaultCompression); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *aesFileWriter) Write(p []byte) (int, error) {
+	n, err := w.comp.Write(p)
+	w.size += uint64(n)
+	return n, err
+}
+
+// Close writes the authentication code and sizes of file, it must be called before adding next file to zip
+func (w *aesFileWriter) Close() error {
+	if err := w.comp.Close(); err != nil {
+		return err
+	}
+	if _, err := w.raw.Write(w.cipher.mac.Sum(nil)[:aesMACLen]); err != nil {
+		return err
+	}
+	w.fh.UncompressedSize64 = w.size
+	w.fh.CompressedSize64 = aesSaltLen + aesVerifierLen + w.cipher.n + aesMACLen
+	w.fh.UncompressedSize = uint32(minUint64(w.fh.UncompressedSize64, 1<<32-1))
+	w.fh.CompressedSize = uint32(minUint64(w.fh.CompressedSize64, 1<<32-1))
+	return nil
+}
+
+// aesCipherWriter encrypts and authenticates the content written to w
+type aesCipherWriter struct {
+	w      io.Writer
+	stream cipher.Stream
+	mac    hash.Hash
+	n      uint64
+}
+
+func (c *aesCipherWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	c.stream.XORKeyStream(buf, p)
+	c.mac.Write(buf)
+	n, err := c.w.Write(buf)
+	c.n += uint64(n)
+	return n, err
+}
+
+// openZipFile opens f of zip, f is decrypted with password if it's encrypted with WinZip AES
+func openZipFile(f *zip.File, password string) (io.ReadCloser, error) {
+	if f.Method != aesMethod {
+		return f.Open()
+	}
+	strength, method, err := aesExtra(f.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(raw)
+	if err != nil {
+		return nil, err
+	}
+	saltLen := 4 + 4*int(strength)
+	if len(content) < saltLen+aesVerifierLen+aesMACLen {
+		return nil, fmt.Errorf("%s: %w", f.Name, zip.ErrFormat)
+	}
+	salt := content[:saltLen]
+	verifier := content[saltLen : saltLen+aesVerifierLen]
+	data := content[saltLen+aesVerifierLen : len(content)-aesMACLen]
+	code := content[len(content)-aesMACLen:]
+
+	encKey, macKey, want := aesKeys(password, salt, 8+8*int(strength))
+	if subtle.ConstantTimeCompare(verifier, want) != 1 {
+		return nil, fmt.Errorf("%s: %w", f.Name, errZipPassword)
+	}
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil)[:aesMACLen], code) {
+		return nil, fmt.Errorf("%s: %w", f.Name, errZipMAC)
+	}
+	stream, err := newAESCTR(encKey)
+	if err != nil {
+		return nil, err
+	}
+	stream.XORKeyStream(data, data)
+	switch method {
+	case zip.Store:
+		return io.NopCloser(bytes.NewReader(data)), nil
+	case zip.Deflate:
+		return flate.NewReader(bytes.NewReader(data)), nil
+	default:
+		return nil, fmt.Errorf("%s: %w", f.Name, zip.ErrAlgorithm)
+	}
+}
+
+// aesExtra returns the strength and actual compression method in extra field of WinZip AES
+func aesExtra(extra []byte) (strength byte, method uint16, err error) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		if id == aesExtraID && size == 7 {
+			strength = extra[4]
+			if strength < 1 || strength > 3 {
+				return 0, 0, zip.ErrAlgorithm
+			}
+			return strength, binary.LittleEndian.Uint16(extra[5:]), nil
+		}
+		extra = extra[size:]
+	}
+	return 0, 0, zip.ErrFormat
+}
+
+func aesKeys(password string, salt []byte, keyLen int) (encKey, macKey, verifier []byte) {
+	key := pbkdf2.Key([]byte(password), salt, aesPBKDF2Rounds, 2*keyLen+aesVerifierLen, sha1.New)
+	return key[:keyLen], key[keyLen : 2*keyLen], key[2*keyLen:]
+}
+
+// aesCTR is the AES-CTR stream of WinZip AES, whose counter starts from 1 and is little-endian
+type aesCTR struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	pos     int
+}
+
+func newAESCTR(key []byte) (*aesCTR, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesCTR{block: block, pos: aes.BlockSize}, nil
+}
+
+func (c *aesCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.pos == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.pos = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.pos]
+		c.pos++
+	}
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,52 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAESZip(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("hack-browser-data,", 100))
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	fw, err := createAESFile(zw, &zip.FileHeader{Name: "password.csv"}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := zr.File[0]
+
+	if _, err := openZipFile(f, "wrong"); !errors.Is(err, errZipPassword) {
+		t.Errorf("openZipFile() with wrong password error = %v, want %v", err, errZipPassword)
+	}
+	rc, err := openZipFile(f, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("openZipFile() = %q, want %q", got, content)
+	}
+}
@@ -0,0 +1,527 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/klauspost/compress/zstd"
+)
+
+const ageExt = ".age"
+
+// archive formats of results
+const (
+	FormatZip    = "zip"
+	FormatTarGz  = "tar.gz"
+	FormatTarZst = "tar.zst"
+)
+
+// ArchiveFormats returns all archive formats of results
+func ArchiveFormats() []string {
+	return []string{FormatZip, FormatTarGz, FormatTarZst}
+}
+
+// CompressOptions are options of compressing results
+type CompressOptions struct {
+	// Format is one of ArchiveFormats, default is zip
+	Format string
+	// Output is the path of archive, default is <dir>/<base of dir>.<format> in dir
+	Output string
+	// Password encrypts files of zip with WinZip AES-256
+	Password string
+	// Recipients are age X25519 public keys which the archive is encrypted to
+	Recipients []string
+	// Files are the slash separated paths relative to dir of files to compress, which are written by outputters.
+	// Other files of dir are neither compressed nor removed.
+	Files []string
+}
+
+// CompressDir streams files of options into an archive, the archived files are removed only after the archive
+// is fully written and verified, and so are their parent dirs in dir if they're empty. It returns the path of archive.
+func CompressDir(dir string, options CompressOptions) (string, error) {
+	if options.Format == "" {
+		options.Format = FormatZip
+	}
+	if !validArchiveFormat(options.Format) {
+		return "", fmt.Errorf("unsupported archive format %s", options.Format)
+	}
+	if options.Password != "" && options.Format != FormatZip {
+		return "", fmt.Errorf("zip password is not supported by %s format", options.Format)
+	}
+	if options.Password != "" && len(options.Recipients) > 0 {
+		return "", errors.New("zip password and age recipients can't be used together")
+	}
+	recipients, err := parseRecipients(options.Recipients)
+	if err != nil {
+		return "", err
+	}
+	output := options.Output
+	if output == "" {
+		output = filepath.Join(dir, filepath.Base(filepath.Clean(dir))+"."+options.Format)
+		if len(recipients) > 0 {
+			output += ageExt
+		}
+	}
+
+	files, dirs, err := statFiles(dir, options.Files)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files to compress in %s", dir)
+	}
+	if err := writeArchive(dir, output, files, options.Format, options.Password, recipients); err != nil {
+		os.Remove(output)
+		return "", err
+	}
+
+	for _, f := range files {
+		if err := os.Remove(filepath.Join(dir, f.name)); err != nil {
+			return output, err
+		}
+	}
+	// remove parent dirs from the deepest, the ones still containing other files are kept
+	for _, d := range dirs {
+		_ = os.Remove(filepath.Join(dir, d))
+	}
+	return output, nil
+}
+
+// archiveFile is a file to be archived, name is the slash separated path relative to the archived dir
+type archiveFile struct {
+	name string
+	info fs.FileInfo
+	hash [sha256.Size]byte
+}
+
+// statFiles returns regular files of names in dir in lexical order, and their parent dirs in dir from the deepest
+func statFiles(dir string, names []string) ([]*archiveFile, []string, error) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	var (
+		files []*archiveFile
+		dirs  []string
+		seen  = make(map[string]bool)
+	)
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return nil, nil, fmt.Errorf("file %s is not in %s", name, dir)
+		}
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, nil, fmt.Errorf("%s is not a regular file", p)
+		}
+		files = append(files, &archiveFile{name: filepath.ToSlash(rel), info: info})
+		for d := filepath.Dir(rel); d != "."; d = filepath.Dir(d) {
+			if !seen[d] {
+				seen[d] = true
+				dirs = append(dirs, d)
+			}
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], string(filepath.Separator)) > strings.Count(dirs[j], string(filepath.Separator))
+	})
+	return files, dirs, nil
+}
+
+// writeArchive writes files into archive of output, then reads it back to verify the content of files
+func writeArchive(dir, output string, files []*archiveFile, format, password string, recipients []age.Recipient) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0o750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(filepath.Clean(output), os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// the hash of written archive is used to verify archive encrypted with age, which can't be read back
+	written := sha256.New()
+	var w io.Writer = io.MultiWriter(out, written)
+	var aw io.WriteCloser
+	if len(recipients) > 0 {
+		if aw, err = age.Encrypt(w, recipients...); err != nil {
+			return err
+		}
+		w = aw
+	}
+	if err := archiveFiles(w, dir, files, format, password); err != nil {
+		return err
+	}
+	if aw != nil {
+		if err := aw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if len(recipients) > 0 {
+		return verifyHash(output, written.Sum(nil))
+	}
+	return verifyArchive(output, files, format, password)
+}
+
+func archiveFiles(w io.Writer, dir string, files []*archiveFile, format, password string) error {
+	var (
+		create func(f *archiveFile) (io.Writer, error)
+		closer func() error
+	)
+	switch format {
+	case FormatZip:
+		zw := zip.NewWriter(w)
+		var last io.Closer
+		create = func(f *archiveFile) (io.Writer, error) {
+			if last != nil {
+				if err := last.Close(); err != nil {
+					return nil, err
+				}
+				last = nil
+			}
+			fh, err := zip.FileInfoHeader(f.info)
+			if err != nil {
+				return nil, err
+			}
+			fh.Name = f.name
+			if password == "" {
+				fh.Method = zip.Deflate
+				return zw.CreateHeader(fh)
+			}
+			aw, err := createAESFile(zw, fh, password)
+			last = aw
+			return aw, err
+		}
+		closer = func() error {
+			if last != nil {
+				if err := last.Close(); err != nil {
+					return err
+				}
+			}
+			return zw.Close()
+		}
+	case FormatTarGz, FormatTarZst:
+		var comp io.WriteCloser
+		var err error
+		if format == FormatTarGz {
+			comp = gzip.NewWriter(w)
+		} else if comp, err = zstd.NewWriter(w); err != nil {
+			return err
+		}
+		tw := tar.NewWriter(comp)
+		create = func(f *archiveFile) (io.Writer, error) {
+			hdr, err := tar.FileInfoHeader(f.info, "")
+			if err != nil {
+				return nil, err
+			}
+			hdr.Name = f.name
+			return tw, tw.WriteHeader(hdr)
+		}
+		closer = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			return comp.Close()
+		}
+	}
+
+	for _, f := range files {
+		fw, err := create(f)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(fw, filepath.Join(dir, filepath.FromSlash(f.name)), f); err != nil {
+			return err
+		}
+	}
+	return closer()
+}
+
+// copyFile streams file of name to w and records the hash of its content
+func copyFile(w io.Writer, name string, f *archiveFile) error {
+	in, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), in)
+	if err != nil {
+		return err
+	}
+	if n != f.info.Size() {
+		return fmt.Errorf("%s is changed while compressing", name)
+	}
+	copy(f.hash[:], h.Sum(nil))
+	return nil
+}
+
+// verifyArchive reads archive of output back and checks all files are in it with the same content
+func verifyArchive(output string, files []*archiveFile, format, password string) error {
+	want := make(map[string]*archiveFile, len(files))
+	for _, f := range files {
+		want[f.name] = f
+	}
+	in, err := os.Open(filepath.Clean(output))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	err = readArchive(in, info.Size(), format, password, func(name string, r io.Reader) error {
+		f, ok := want[name]
+		if !ok {
+			return fmt.Errorf("unexpected file %s in archive", name)
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return err
+		}
+		if !bytes.Equal(h.Sum(nil), f.hash[:]) {
+			return fmt.Errorf("content of %s in archive mismatch", name)
+		}
+		delete(want, name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("verify archive %s: %w", output, err)
+	}
+	if len(want) > 0 {
+		return fmt.Errorf("verify archive %s: %d files are missing", output, len(want))
+	}
+	return nil
+}
+
+func verifyHash(name string, want []byte) error {
+	in, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, in); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), want) {
+		return fmt.Errorf("verify archive %s: content mismatch", name)
+	}
+	return nil
+}
+
+// readArchive calls fn with each file of archive in format
+func readArchive(r io.ReaderAt, size int64, format, password string, fn func(name string, r io.Reader) error) error {
+	switch format {
+	case FormatZip:
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return err
+		}
+		for _, f := range zr.File {
+			if strings.HasSuffix(f.Name, "/") {
+				continue
+			}
+			if f.Flags&zipEncrypted != 0 && f.Method != aesMethod {
+				return fmt.Errorf("%s: %w", f.Name, zip.ErrAlgorithm)
+			}
+			if f.Method == aesMethod && password == "" {
+				return fmt.Errorf("%s is encrypted, zip password is required", f.Name)
+			}
+			rc, err := openZipFile(f, password)
+			if err != nil {
+				return err
+			}
+			err = fn(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTarGz, FormatTarZst:
+		var comp io.Reader
+		sr := io.NewSectionReader(r, 0, size)
+		if format == FormatTarGz {
+			gr, err := gzip.NewReader(sr)
+			if err != nil {
+				return err
+			}
+			defer gr.Close()
+			comp = gr
+		} else {
+			zr, err := zstd.NewReader(sr)
+			if err != nil {
+				return err
+			}
+			defer zr.Close()
+			comp = zr
+		}
+		tr := tar.NewReader(comp)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := fn(hdr.Name, tr); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+func validArchiveFormat(format string) bool {
+	for _, f := range ArchiveFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveFormat returns the format of archive by the extension of filename
+func archiveFormat(filename string) (string, error) {
+	name := strings.TrimSuffix(filename, ageExt)
+	for _, f := range ArchiveFormats() {
+		if strings.HasSuffix(name, "."+f) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown archive format of %s", filename)
+}
+
+// ExtractOptions are options of extracting encrypted results
+type ExtractOptions struct {
+	// Password decrypts files of zip encrypted with WinZip AES
+	Password string
+	// Identities are files of age X25519 private keys, which decrypt archive encrypted with age
+	Identities []string
+}
+
+// ExtractArchive decrypts the archive of results if it's encrypted, and extracts its files into dir
+func ExtractArchive(filename, dir string, options ExtractOptions) error {
+	format, err := archiveFormat(filename)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	var (
+		r    io.ReaderAt = in
+		size             = info.Size()
+	)
+	if strings.HasSuffix(filename, ageExt) {
+		identities, err := parseIdentities(options.Identities)
+		if err != nil {
+			return err
+		}
+		ar, err := age.Decrypt(in, identities...)
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(ar)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(content), int64(len(content))
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	return readArchive(r, size, format, options.Password, func(name string, r io.Reader) error {
+		return extractFile(dir, name, r)
+	})
+}
+
+func extractFile(dir, name string, r io.Reader) error {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("illegal file path %s in archive", name)
+	}
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(p, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func parseRecipients(keys []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for _, key := range keys {
+		r, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+func parseIdentities(files []string) ([]age.Identity, error) {
+	var identities []age.Identity
+	for _, name := range files {
+		f, err := os.Open(filepath.Clean(name))
+		if err != nil {
+			return nil, err
+		}
+		ids, err := age.ParseIdentities(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parse identity file %s: %w", name, err)
+		}
+		identities = append(identities, ids...)
+	}
+	if len(identities) == 0 {
+		return nil, errors.New("age identity is required")
+	}
+	return identities, nil
+}
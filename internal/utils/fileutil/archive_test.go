@@ -0,0 +1,70 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressDir(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range ArchiveFormats() {
+		dir := filepath.Join(t.TempDir(), "results")
+		files := map[string]string{
+			"chrome_default_password.csv":     "url,username,password",
+			"firefox/default/history.json":    "[]",
+			"firefox/default/nested/file.txt": "nested",
+		}
+		for name, content := range files {
+			p := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// files which aren't written by outputters are neither compressed nor removed
+		foreign := filepath.Join(dir, "firefox", "notes.txt")
+		if err := os.WriteFile(foreign, []byte("notes"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+
+		archive, err := CompressDir(dir, CompressOptions{Format: format, Files: names})
+		if err != nil {
+			t.Fatalf("CompressDir(%s) error %s", format, err)
+		}
+		if want := filepath.Join(dir, "results."+format); archive != want {
+			t.Errorf("CompressDir(%s) = %s, want %s", format, archive, want)
+		}
+		if got, err := os.ReadFile(foreign); err != nil || string(got) != "notes" {
+			t.Errorf("CompressDir(%s) removed foreign file: %v", format, err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+			t.Errorf("CompressDir(%s) left %d entries in dir, want archive and firefox", format, len(entries))
+		}
+		if FolderExists(filepath.Join(dir, "firefox", "default")) {
+			t.Errorf("CompressDir(%s) kept emptied dir firefox/default", format)
+		}
+
+		extract := t.TempDir()
+		if err := ExtractArchive(archive, extract, ExtractOptions{}); err != nil {
+			t.Fatalf("ExtractArchive(%s) error %s", format, err)
+		}
+		for name, content := range files {
+			got, err := os.ReadFile(filepath.Join(extract, filepath.FromSlash(name)))
+			if err != nil || string(got) != content {
+				t.Errorf("extracted %s of %s = %q, %v, want %q", name, format, got, err, content)
+			}
+		}
+		if FileExists(filepath.Join(extract, "firefox", "notes.txt")) {
+			t.Errorf("archive of %s contains foreign file", format)
+		}
+	}
+}
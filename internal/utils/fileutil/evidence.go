@@ -0,0 +1,89 @@
+package fileutil
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is only recorded for legacy tooling
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Evidence is a file copied from browser profile, the source is hashed before copying and the copy afterwards.
+// It's verified if they match and the source isn't changed during copy.
+type Evidence struct {
+	Source   *EvidenceFile `json:"source"`
+	Copy     *EvidenceFile `json:"copy"`
+	Verified bool          `json:"verified"`
+}
+
+// EvidenceFile is the hashes, size and MAC times of a file, times which aren't supported by
+// the platform are omitted, e.g. created time on Linux.
+type EvidenceFile struct {
+	Path     string     `json:"path"`
+	Size     int64      `json:"size"`
+	SHA256   string     `json:"sha256"`
+	MD5      string     `json:"md5"`
+	Modified time.Time  `json:"modified"`
+	Accessed *time.Time `json:"accessed,omitempty"`
+	Changed  *time.Time `json:"changed,omitempty"`
+	Created  *time.Time `json:"created,omitempty"`
+}
+
+// ErrSourceChanged is the error of evidence which isn't verified
+var ErrSourceChanged = errors.New("source changed during copy")
+
+// newEvidenceFile hashes file, it's stated before reading as reading updates the access time
+func newEvidenceFile(name string) (*EvidenceFile, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sha, md := sha256.New(), md5.New()
+	if _, err := io.Copy(io.MultiWriter(sha, md), f); err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		path = name
+	}
+	e := &EvidenceFile{
+		Path:     path,
+		Size:     info.Size(),
+		SHA256:   hex.EncodeToString(sha.Sum(nil)),
+		MD5:      hex.EncodeToString(md.Sum(nil)),
+		Modified: info.ModTime().UTC(),
+	}
+	accessed, changed, created := fileTimes(info)
+	e.Accessed, e.Changed, e.Created = utcTime(accessed), utcTime(changed), utcTime(created)
+	return e, nil
+}
+
+func utcTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// newEvidence returns the evidence of the copy of source, which is hashed before copying
+func newEvidence(src string, source *EvidenceFile, dst string) (*Evidence, error) {
+	copied, err := newEvidenceFile(dst)
+	if err != nil {
+		return nil, err
+	}
+	e := &Evidence{Source: source, Copy: copied}
+	// the source is changed during copy if its size or modified time is changed
+	after, err := os.Stat(src)
+	e.Verified = err == nil && after.Size() == source.Size && after.ModTime().UTC().Equal(source.Modified) &&
+		copied.Size == source.Size && copied.SHA256 == source.SHA256 && copied.MD5 == source.MD5
+	return e, nil
+}
@@ -0,0 +1,78 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFileEvidence(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "History"), filepath.Join(dir, "history")
+	if err := os.WriteFile(src, []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := CopyFile(src, dst, CopyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e != nil || !FileExists(dst) {
+		t.Fatalf("got evidence %+v of copy without evidence", e)
+	}
+	if e, err = CopyFile(src, dst, CopyOptions{Evidence: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !e.Verified {
+		t.Error("evidence of unchanged source isn't verified")
+	}
+	want := EvidenceFile{
+		Path: src,
+		Size: 4,
+		// SHA-256 and MD5 of test
+		SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		MD5:    "098f6bcd4621d373cade4e832627b4f6",
+	}
+	for _, f := range []*EvidenceFile{e.Source, e.Copy} {
+		if f.Size != want.Size || f.SHA256 != want.SHA256 || f.MD5 != want.MD5 {
+			t.Errorf("got %s size %d sha256 %s md5 %s, want size %d sha256 %s md5 %s",
+				f.Path, f.Size, f.SHA256, f.MD5, want.Size, want.SHA256, want.MD5)
+		}
+		if f.Modified.IsZero() {
+			t.Errorf("got zero modified time of %s", f.Path)
+		}
+	}
+	if e.Source.Path != want.Path || e.Copy.Path != dst {
+		t.Errorf("got source %s and copy %s, want %s and %s", e.Source.Path, e.Copy.Path, want.Path, dst)
+	}
+}
+
+func TestCopyDirEvidence(t *testing.T) {
+	t.Parallel()
+
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "localStorage")
+	for _, name := range []string{"000003.log", "LOCK", filepath.Join("sub", "CURRENT")} {
+		if err := os.MkdirAll(filepath.Join(src, filepath.Dir(name)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	evidence, err := CopyDir(src, dst, "lock", CopyOptions{Evidence: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evidence) != 2 {
+		t.Fatalf("got %d evidence, want 2", len(evidence))
+	}
+	if FileExists(filepath.Join(dst, "LOCK")) || !FileExists(filepath.Join(dst, "sub", "CURRENT")) {
+		t.Error("CopyDir() didn't skip LOCK or keep relative paths")
+	}
+	for _, e := range evidence {
+		if !e.Verified || e.Source.SHA256 != e.Copy.SHA256 {
+			t.Errorf("evidence of %s isn't verified", e.Source.Path)
+		}
+	}
+}
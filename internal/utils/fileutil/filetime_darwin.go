@@ -0,0 +1,21 @@
+//go:build darwin
+
+package fileutil
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes returns the access, change and creation time of file
+func fileTimes(info os.FileInfo) (accessed, changed, created time.Time) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	accessed = time.Unix(stat.Atimespec.Unix())
+	changed = time.Unix(stat.Ctimespec.Unix())
+	created = time.Unix(stat.Birthtimespec.Unix())
+	return
+}
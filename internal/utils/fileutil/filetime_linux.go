@@ -0,0 +1,20 @@
+//go:build linux
+
+package fileutil
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes returns the access, change and creation time of file, creation time isn't available on Linux
+func fileTimes(info os.FileInfo) (accessed, changed, created time.Time) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	accessed = time.Unix(stat.Atim.Unix())
+	changed = time.Unix(stat.Ctim.Unix())
+	return
+}
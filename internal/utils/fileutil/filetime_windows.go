@@ -0,0 +1,20 @@
+//go:build windows
+
+package fileutil
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes returns the access, change and creation time of file, change time of metadata isn't available on Windows
+func fileTimes(info os.FileInfo) (accessed, changed, created time.Time) {
+	attr, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return
+	}
+	accessed = time.Unix(0, attr.LastAccessTime.Nanoseconds())
+	created = time.Unix(0, attr.CreationTime.Nanoseconds())
+	return
+}
@@ -1,16 +1,11 @@
 package fileutil
 
 import (
-	"archive/zip"
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
-
-	cp "github.com/otiai10/copy"
 )
 
 // FileExists checks if the file exists in the provided path
@@ -58,53 +53,91 @@ func ReadFile(filename string) (string, error) {
 	return string(s), err
 }
 
+// CopyOptions are the options of copying files from browser profile
+type CopyOptions struct {
+	// Evidence hashes the source before copying and the copy afterwards, there is no evidence without it
+	Evidence bool
+}
+
 // CopyDir copies the directory from the source to the destination
-// skip the file if you don't want to copy
-func CopyDir(src, dst, skip string) error {
-	s := cp.Options{Skip: func(src string) (bool, error) {
-		return strings.HasSuffix(strings.ToLower(src), skip), nil
-	}}
-	return cp.Copy(src, dst, s)
+// skip the file if you don't want to copy, it returns the evidence of copied files if it's enabled
+func CopyDir(src, dst, skip string, options CopyOptions) ([]*Evidence, error) {
+	return copyFiles(src, dst, options, func(path string) bool {
+		return !strings.HasSuffix(strings.ToLower(path), skip)
+	})
 }
 
-// CopyDirHasSuffix copies the directory from the source to the destination
-// contain is the file if you want to copy, and rename copied filename with dir/index_filename
-func CopyDirHasSuffix(src, dst, suffix string) error {
+// CopyDirHasSuffix copies the files which have suffix in the directory from the source to the destination,
+// copied files keep their paths relative to the source
+func CopyDirHasSuffix(src, dst, suffix string, options CopyOptions) ([]*Evidence, error) {
+	return copyFiles(src, dst, options, func(path string) bool {
+		return strings.HasSuffix(strings.ToLower(filepath.Base(path)), suffix)
+	})
+}
+
+// copyFiles copies the files matched in the directory from the source to the destination,
+// copied files keep their paths relative to the source
+func copyFiles(src, dst string, options CopyOptions, match func(path string) bool) ([]*Evidence, error) {
 	var files []string
 	err := filepath.Walk(src, func(path string, f os.FileInfo, err error) error {
-		if !f.IsDir() && strings.HasSuffix(strings.ToLower(f.Name()), suffix) {
+		if err != nil {
+			return err
+		}
+		if !f.IsDir() && match(path) {
 			files = append(files, path)
 		}
-		return err
+		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := os.MkdirAll(dst, 0o700); err != nil {
-		return err
+		return nil, err
 	}
-	for index, file := range files {
-		// p = dir/index_file
-		p := fmt.Sprintf("%s/%d_%s", dst, index, BaseDir(file))
-		err = CopyFile(file, p)
+	var evidence []*Evidence
+	for _, file := range files {
+		rel, err := filepath.Rel(src, file)
 		if err != nil {
-			return err
+			return evidence, err
+		}
+		p := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			return evidence, err
+		}
+		e, err := CopyFile(file, p, options)
+		if err != nil {
+			return evidence, err
+		}
+		if e != nil {
+			evidence = append(evidence, e)
 		}
 	}
-	return nil
+	return evidence, nil
 }
 
-// CopyFile copies the file from the source to the destination
-func CopyFile(src, dst string) error {
-	s, err := os.ReadFile(src)
+// CopyFile copies the file from the source to the destination,
+// it returns the evidence of source and copy if it's enabled
+func CopyFile(src, dst string, options CopyOptions) (*Evidence, error) {
+	if !options.Evidence {
+		return nil, copyLocal(src, dst)
+	}
+	source, err := newEvidenceFile(src)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := copyLocal(src, dst); err != nil {
+		return nil, err
 	}
-	err = os.WriteFile(dst, s, 0o600)
+	return newEvidence(src, source, dst)
+}
+
+// copyLocal copies the file from the source to the destination which is only readable by the user
+func copyLocal(src, dst string) error {
+	s, err := os.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	return nil
+	return os.WriteFile(dst, s, 0o600)
 }
 
 // ItemName returns the filename from the provided path
@@ -118,6 +151,43 @@ func BrowserName(browser, user string) string {
 	return strings.ToLower(fmt.Sprintf("%s_%s", replace.Replace(browser), replace.Replace(user)))
 }
 
+// SafeName returns name which is safe as a file or folder name on all platforms,
+// reserved characters are replaced with underscore and reserved names of windows are suffixed with underscore
+func SafeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	// windows doesn't allow trailing spaces or dots
+	name = strings.TrimRight(name, " .")
+	if name == "" {
+		return "_"
+	}
+	// reserved names are reserved with any extension, e.g. nul.txt, so base name is suffixed
+	base, ext, hasExt := strings.Cut(name, ".")
+	if isReservedName(strings.ToUpper(strings.TrimRight(base, " "))) {
+		name = base + "_"
+		if hasExt {
+			name += "." + ext
+		}
+	}
+	return name
+}
+
+// isReservedName reports whether name is a reserved device name of windows, e.g. CON and COM1
+func isReservedName(name string) bool {
+	switch name {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(name) == 4 && (strings.HasPrefix(name, "COM") || strings.HasPrefix(name, "LPT")) {
+		return name[3] >= '1' && name[3] <= '9'
+	}
+	return false
+}
+
 // ParentDir returns the parent directory of the provided path
 func ParentDir(p string) string {
 	return filepath.Dir(filepath.Clean(p))
@@ -132,45 +202,3 @@ func BaseDir(p string) string {
 func ParentBaseDir(p string) string {
 	return BaseDir(ParentDir(p))
 }
-
-// CompressDir compresses the directory into a zip file
-func CompressDir(dir string) error {
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return err
-	}
-	b := new(bytes.Buffer)
-	zw := zip.NewWriter(b)
-	for _, f := range files {
-		fw, err := zw.Create(f.Name())
-		if err != nil {
-			return err
-		}
-		name := path.Join(dir, f.Name())
-		content, err := os.ReadFile(name)
-		if err != nil {
-			return err
-		}
-		_, err = fw.Write(content)
-		if err != nil {
-			return err
-		}
-		err = os.Remove(name)
-		if err != nil {
-			return err
-		}
-	}
-	if err := zw.Close(); err != nil {
-		return err
-	}
-	filename := filepath.Join(dir, fmt.Sprintf("%s.zip", dir))
-	outFile, err := os.Create(filepath.Clean(filename))
-	if err != nil {
-		return err
-	}
-	_, err = b.WriteTo(outFile)
-	if err != nil {
-		return err
-	}
-	return outFile.Close()
-}
@@ -0,0 +1,32 @@
+package fileutil
+
+import "testing"
+
+func TestSafeName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{"Default", "Default"},
+		{"Profile 1", "Profile 1"},
+		{"abc.default-release", "abc.default-release"},
+		{`a/b\c:d*e?f"g<h>i|j`, "a_b_c_d_e_f_g_h_i_j"},
+		{"tab\there", "tab_here"},
+		{"trailing. ", "trailing"},
+		{"..", "_"},
+		{"", "_"},
+		{"CON", "CON_"},
+		{"nul.txt", "nul_.txt"},
+		{"Com1", "Com1_"},
+		{"COM0", "COM0"},
+		{"LPT9", "LPT9_"},
+		{"CONSOLE", "CONSOLE"},
+	}
+	for _, tc := range testCases {
+		if got := SafeName(tc.name); got != tc.want {
+			t.Errorf("SafeName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
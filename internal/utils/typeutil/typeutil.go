@@ -1,7 +1,9 @@
 package typeutil
 
 import (
+	"strings"
 	"time"
+	"unicode"
 
 	"golang.org/x/exp/constraints"
 )
@@ -51,3 +53,20 @@ func TimeEpoch(epoch int64) time.Time {
 	}
 	return t
 }
+
+// SnakeCase converts name of Go identifier to snake case, e.g. LoginURL to login_url
+func SnakeCase(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(r[i-1]) || unicode.IsDigit(r[i-1]) ||
+				(i+1 < len(r) && unicode.IsUpper(r[i-1]) && unicode.IsLower(r[i+1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -22,3 +22,22 @@ func TestReverse(t *testing.T) {
 		}
 	}
 }
+
+func TestSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"URL":           "url",
+		"LoginURL":      "login_url",
+		"IsHTTPOnly":    "is_http_only",
+		"UserContextID": "user_context_id",
+		"CreateDate":    "create_date",
+		"SourcePort":    "source_port",
+		"Name":          "name",
+	}
+	for name, want := range testCases {
+		if got := SnakeCase(name); got != want {
+			t.Errorf("SnakeCase(%s) = %s, want %s", name, got, want)
+		}
+	}
+}